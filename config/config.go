@@ -11,6 +11,11 @@ type GlobalConfigList struct {
 	LogOutputPath string
 	JQuantsMailAddress string
 	JQuantsPassword string
+	JQuantsPlan string
+	JQuantsTokenFile string
+	JQuantsTokenKey string
+	JQuantsCacheDir string
+	WarehouseDir string
 }
 var GlobalConfig GlobalConfigList
 
@@ -27,5 +32,10 @@ func init() {
 		LogOutputPath: os.Getenv("LOG_OUTPUT_PATH"),
 		JQuantsMailAddress: os.Getenv("J_QUANTS_MAIL_ADDRESS"),
 		JQuantsPassword: os.Getenv("J_QUANTS_PASSWORD"),
+		JQuantsPlan: os.Getenv("J_QUANTS_PLAN"),
+		JQuantsTokenFile: os.Getenv("J_QUANTS_TOKEN_FILE"),
+		JQuantsTokenKey: os.Getenv("J_QUANTS_TOKEN_KEY"),
+		JQuantsCacheDir: os.Getenv("J_QUANTS_CACHE_DIR"),
+		WarehouseDir: os.Getenv("WAREHOUSE_DIR"),
 	}
 }
\ No newline at end of file
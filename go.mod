@@ -2,4 +2,7 @@ module Go-AutoTrade
 
 go 1.22.2
 
-require github.com/joho/godotenv v1.5.1
+require (
+	github.com/joho/godotenv v1.5.1
+	golang.org/x/crypto v0.33.0
+)
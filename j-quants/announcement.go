@@ -0,0 +1,64 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// EarningsAnnouncement は /fins/announcement の決算発表予定1件分。翌営業日以降の予定が返る
+type EarningsAnnouncement struct {
+	Date          string `json:"Date"` // 発表予定日。未定の場合は空
+	Code          string `json:"Code"`
+	CompanyName   string `json:"CompanyName"`
+	FiscalYear    string `json:"FiscalYear"` // 例: "3月31日"
+	SectorName    string `json:"SectorName"`
+	FiscalQuarter string `json:"FiscalQuarter"` // 例: "第１四半期"
+	Section       string `json:"Section"`       // 市場区分
+}
+
+// announcementResponse : JSON全体を受け取るための構造
+type announcementResponse struct {
+	Announcement  []EarningsAnnouncement `json:"announcement"`
+	PaginationKey string                 `json:"pagination_key"`
+}
+
+// GetAnnouncements は /fins/announcement を全ページ取得し、[]EarningsAnnouncement を返す
+func (c *JQuantsClient) GetAnnouncements() ([]EarningsAnnouncement, error) {
+	return c.GetAnnouncementsWithContext(context.Background())
+}
+
+// GetAnnouncementsWithContext は GetAnnouncements の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetAnnouncementsWithContext(ctx context.Context) ([]EarningsAnnouncement, error) {
+	return DoPaginatedGetWithContext[EarningsAnnouncement](ctx, c, c.endpoint("/fins/announcement"), url.Values{}, extractAnnouncements)
+}
+
+// StreamAnnouncements は /fins/announcement を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamAnnouncements(ctx context.Context, handle PageHandler[EarningsAnnouncement]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/fins/announcement"), url.Values{}, extractAnnouncements, handle)
+}
+
+func extractAnnouncements(respBytes []byte) ([]EarningsAnnouncement, string, error) {
+	var r announcementResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal announcement: %w", err)
+	}
+	return r.Announcement, r.PaginationKey, nil
+}
+
+// EarningsDatesBetween は from から to まで (両端を含む, "2006-01-02" 形式) に決算発表を予定している銘柄と、その発表日を返す。
+// 決算を跨いで保有しないようにポジションを絞る用途を想定している
+func EarningsDatesBetween(anns []EarningsAnnouncement, from, to string) map[string]string {
+	result := make(map[string]string)
+	for _, a := range anns {
+		if a.Date == "" || a.Date < from || a.Date > to {
+			continue
+		}
+		if prev, ok := result[a.Code]; !ok || a.Date < prev {
+			result[a.Code] = a.Date
+		}
+	}
+	return result
+}
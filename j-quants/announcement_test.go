@@ -0,0 +1,49 @@
+package jquants
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetAnnouncements(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		// 条件は指定できないので、2ページ目以降も pagination_key だけを送る
+		if r.URL.Path != "/fins/announcement" || len(q) > 1 || (len(q) == 1 && !q.Has("pagination_key")) {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		switch q.Get("pagination_key") {
+		case "":
+			w.Write([]byte(`{"announcement":[{"Date":"2024-05-08","Code":"72030","CompanyName":"トヨタ自動車","FiscalYear":"3月31日","SectorName":"輸送用機器","FiscalQuarter":"通期","Section":"プライム"}],"pagination_key":"next"}`))
+		case "next":
+			w.Write([]byte(`{"announcement":[{"Date":"","Code":"91040","CompanyName":"商船三井","FiscalYear":"3月31日","SectorName":"海運業","FiscalQuarter":"通期","Section":"プライム"}]}`))
+		default:
+			t.Errorf("Unexpected pagination key: %s", q.Get("pagination_key"))
+		}
+	}))
+
+	anns, err := c.GetAnnouncements()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anns) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(anns))
+	}
+	if anns[0].Date != "2024-05-08" || anns[0].FiscalQuarter != "通期" || anns[1].Code != "91040" || anns[1].Date != "" {
+		t.Errorf("Unexpected announcements: %+v", anns)
+	}
+}
+
+func TestEarningsDatesBetween(t *testing.T) {
+	anns := []EarningsAnnouncement{
+		{Date: "2024-05-08", Code: "72030"},
+		{Date: "2024-05-20", Code: "91040"},
+		{Date: "", Code: "99990"},
+	}
+	got := EarningsDatesBetween(anns, "2024-05-01", "2024-05-10")
+	if len(got) != 1 || got["72030"] != "2024-05-08" {
+		t.Errorf("Unexpected earnings dates: %v", got)
+	}
+}
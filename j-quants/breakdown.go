@@ -0,0 +1,115 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// Breakdown は /markets/breakdown の1銘柄・1日分の売買内訳。Value は売買代金 (円)、Volume は株数
+type Breakdown struct {
+	Date string `json:"Date"`
+	Code string `json:"Code"`
+
+	LongSellValue               float64 `json:"LongSellValue"`               // 実売り
+	ShortSellWithoutMarginValue float64 `json:"ShortSellWithoutMarginValue"` // 空売り (信用新規売りを除く)
+	MarginSellNewValue          float64 `json:"MarginSellNewValue"`          // 信用新規売り
+	MarginSellCloseValue        float64 `json:"MarginSellCloseValue"`        // 信用返済売り
+	LongBuyValue                float64 `json:"LongBuyValue"`                // 現物買い
+	MarginBuyNewValue           float64 `json:"MarginBuyNewValue"`           // 信用新規買い
+	MarginBuyCloseValue         float64 `json:"MarginBuyCloseValue"`         // 信用返済買い
+
+	LongSellVolume               float64 `json:"LongSellVolume"`
+	ShortSellWithoutMarginVolume float64 `json:"ShortSellWithoutMarginVolume"`
+	MarginSellNewVolume          float64 `json:"MarginSellNewVolume"`
+	MarginSellCloseVolume        float64 `json:"MarginSellCloseVolume"`
+	LongBuyVolume                float64 `json:"LongBuyVolume"`
+	MarginBuyNewVolume           float64 `json:"MarginBuyNewVolume"`
+	MarginBuyCloseVolume         float64 `json:"MarginBuyCloseVolume"`
+}
+
+// SellValue は売り全体の売買代金を返す
+func (b Breakdown) SellValue() float64 {
+	return b.LongSellValue + b.ShortSellWithoutMarginValue + b.MarginSellNewValue + b.MarginSellCloseValue
+}
+
+// ShortSellValue は空売り (信用新規売りを含む) の売買代金を返す
+func (b Breakdown) ShortSellValue() float64 {
+	return b.ShortSellWithoutMarginValue + b.MarginSellNewValue
+}
+
+// ShortSellingRatio は売買代金ベースで売り全体に占める空売りの割合を返す。売りが無ければ false。
+// 業種別の空売り比率 (ShortSelling.ShortSellingRatio) と同じく、信用新規売りも空売りに含める
+func (b Breakdown) ShortSellingRatio() (float64, bool) {
+	total := b.SellValue()
+	if total == 0 {
+		return 0, false
+	}
+	return b.ShortSellValue() / total, true
+}
+
+// ShortSellingRatioPoint は1銘柄・1日分の空売り比率
+type ShortSellingRatioPoint struct {
+	Date  string
+	Ratio float64
+}
+
+// DailyShortSellingRatios は銘柄ごとの日次の空売り比率 (Date の昇順) を返す。売りが無かった日は含めない
+func DailyShortSellingRatios(breakdowns []Breakdown) map[string][]ShortSellingRatioPoint {
+	result := make(map[string][]ShortSellingRatioPoint)
+	for _, b := range breakdowns {
+		ratio, ok := b.ShortSellingRatio()
+		if !ok {
+			continue
+		}
+		result[b.Code] = append(result[b.Code], ShortSellingRatioPoint{Date: b.Date, Ratio: ratio})
+	}
+	for _, points := range result {
+		sort.Slice(points, func(i, j int) bool { return points[i].Date < points[j].Date })
+	}
+	return result
+}
+
+// breakdownResponse : JSON全体を受け取るための構造
+type breakdownResponse struct {
+	Breakdown     []Breakdown `json:"breakdown"`
+	PaginationKey string      `json:"pagination_key"`
+}
+
+// GetBreakdownParams : クエリパラメータ。Code か Date のどちらかは必須
+type GetBreakdownParams struct {
+	Code string
+	Date string
+	From string
+	To   string
+}
+
+// GetBreakdown は /markets/breakdown を全ページ取得し、[]Breakdown を返す
+func (c *JQuantsClient) GetBreakdown(params GetBreakdownParams) ([]Breakdown, error) {
+	return c.GetBreakdownWithContext(context.Background(), params)
+}
+
+// GetBreakdownWithContext は GetBreakdown の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetBreakdownWithContext(ctx context.Context, params GetBreakdownParams) ([]Breakdown, error) {
+	return DoPaginatedGetWithContext[Breakdown](ctx, c, c.endpoint("/markets/breakdown"), params.values(), extractBreakdown)
+}
+
+// StreamBreakdown は /markets/breakdown を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamBreakdown(ctx context.Context, params GetBreakdownParams, handle PageHandler[Breakdown]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/markets/breakdown"), params.values(), extractBreakdown, handle)
+}
+
+func (params GetBreakdownParams) values() url.Values {
+	return codeDateRangeValues(params.Code, params.Date, params.From, params.To)
+}
+
+func extractBreakdown(respBytes []byte) ([]Breakdown, string, error) {
+	var r breakdownResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal breakdown: %w", err)
+	}
+	return r.Breakdown, r.PaginationKey, nil
+}
@@ -0,0 +1,35 @@
+package jquants
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetBreakdownAndShortSellingRatios(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/markets/breakdown" || q.Get("code") != "7203" || q.Get("from") != "2024-01-04" {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		w.Write([]byte(`{"breakdown":[
+			{"Date":"2024-01-05","Code":"72030","LongSellValue":600,"ShortSellWithoutMarginValue":200,"MarginSellNewValue":100,"MarginSellCloseValue":100},
+			{"Date":"2024-01-04","Code":"72030","LongSellValue":500,"ShortSellWithoutMarginValue":300,"MarginSellNewValue":200,"MarginSellCloseValue":0},
+			{"Date":"2024-01-04","Code":"13010"}
+		]}`))
+	}))
+	breakdowns, err := c.GetBreakdown(GetBreakdownParams{Code: "7203", From: "2024-01-04", To: "2024-01-05"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ratios := DailyShortSellingRatios(breakdowns)
+	points := ratios["72030"]
+	if len(points) != 2 || points[0].Date != "2024-01-04" || points[0].Ratio != 0.5 || points[1].Ratio != 0.3 {
+		t.Errorf("Unexpected ratios: %+v", points)
+	}
+	if _, ok := ratios["13010"]; ok {
+		t.Error("Expected a day without sells to be skipped")
+	}
+}
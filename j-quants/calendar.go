@@ -0,0 +1,277 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// JST は東証の営業日を判定するためのタイムゾーン
+var JST = time.FixedZone("JST", 9*60*60)
+
+const dateLayout = "2006-01-02"
+
+// Calendar は東証の営業日カレンダー。/markets/trading_calendar の結果から作る。
+// 取得した範囲外の日付は営業日ではないものとして扱うので、Covers で範囲を確認すること
+type Calendar struct {
+	days        map[string]HolidayDivision // キーは "2006-01-02"
+	first, last time.Time
+}
+
+// NewCalendar は取得済みのカレンダーから Calendar を作る
+func NewCalendar(days []TradingCalendarDay) (*Calendar, error) {
+	if len(days) == 0 {
+		return nil, fmt.Errorf("trading calendar is empty")
+	}
+	c := &Calendar{days: make(map[string]HolidayDivision, len(days))}
+	for _, d := range days {
+		t, err := ParseDate(d.Date)
+		if err != nil {
+			return nil, err
+		}
+		c.days[t.Format(dateLayout)] = d.HolidayDivision
+		if c.first.IsZero() || t.Before(c.first) {
+			c.first = t
+		}
+		if t.After(c.last) {
+			c.last = t
+		}
+	}
+	return c, nil
+}
+
+// ParseDate は "2006-01-02" または "20060102" 形式の日付を JST の0時として読む
+func ParseDate(s string) (time.Time, error) {
+	for _, layout := range []string{dateLayout, "20060102"} {
+		if t, err := time.ParseInLocation(layout, s, JST); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %q", s)
+}
+
+// Date は J-Quants の日付。JST の0時として持ち、JSON では "2006-01-02" 形式で読み書きする。
+// null, 空文字, "-" はゼロ値になる
+type Date struct {
+	time.Time
+}
+
+// DateOf は t を JST の日付に丸めた Date を返す
+func DateOf(t time.Time) Date {
+	return Date{truncateDay(t)}
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	s, err := nullString(b)
+	if err != nil {
+		return err
+	}
+	if s == "" || s == "-" {
+		*d = Date{}
+		return nil
+	}
+	t, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = Date{t}
+	return nil
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// String は "2006-01-02" 形式の文字列を返す。ゼロ値は空文字
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(dateLayout)
+}
+
+// truncateDay は t を JST の日付 (0時) に丸める
+func truncateDay(t time.Time) time.Time {
+	t = t.In(JST)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, JST)
+}
+
+// Covers は t がカレンダーの範囲内かどうかを返す
+func (c *Calendar) Covers(t time.Time) bool {
+	d := truncateDay(t)
+	return !d.Before(c.first) && !d.After(c.last)
+}
+
+// Range はカレンダーの最初と最後の日付を返す
+func (c *Calendar) Range() (first, last time.Time) {
+	return c.first, c.last
+}
+
+// Contiguous は最初の日から最後の日までが1日も欠けずに揃っているかどうかを返す
+func (c *Calendar) Contiguous() bool {
+	for d := c.first; !d.After(c.last); d = d.AddDate(0, 0, 1) {
+		if _, ok := c.days[d.Format(dateLayout)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Calendar) division(t time.Time) HolidayDivision {
+	return c.days[truncateDay(t).Format(dateLayout)]
+}
+
+// IsTradingDay は t が東証の営業日 (半日立会日を含む) かどうかを返す
+func (c *Calendar) IsTradingDay(t time.Time) bool {
+	div := c.division(t)
+	return div == HolidayDivisionBusinessDay || div == HolidayDivisionHalfDay
+}
+
+// IsHalfDay は t が半日立会日 (大発会・大納会など) かどうかを返す
+func (c *Calendar) IsHalfDay(t time.Time) bool {
+	return c.division(t) == HolidayDivisionHalfDay
+}
+
+// NextTradingDay は t より後の最初の営業日を返す。カレンダーの範囲内に無ければ false
+func (c *Calendar) NextTradingDay(t time.Time) (time.Time, bool) {
+	for d := truncateDay(t).AddDate(0, 0, 1); !d.After(c.last); d = d.AddDate(0, 0, 1) {
+		if c.IsTradingDay(d) {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// PrevTradingDay は t より前の最後の営業日を返す。カレンダーの範囲内に無ければ false
+func (c *Calendar) PrevTradingDay(t time.Time) (time.Time, bool) {
+	for d := truncateDay(t).AddDate(0, 0, -1); !d.Before(c.first); d = d.AddDate(0, 0, -1) {
+		if c.IsTradingDay(d) {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// TradingDaysBetween は from から to まで (両端を含む) の営業日を古い順に返す
+func (c *Calendar) TradingDaysBetween(from, to time.Time) []time.Time {
+	var result []time.Time
+	for d := truncateDay(from); !d.After(truncateDay(to)); d = d.AddDate(0, 0, 1) {
+		if c.IsTradingDay(d) {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// Days はカレンダーの中身を日付順に返す
+func (c *Calendar) Days() []TradingCalendarDay {
+	days := make([]TradingCalendarDay, 0, len(c.days))
+	for date, div := range c.days {
+		days = append(days, TradingCalendarDay{Date: date, HolidayDivision: div})
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date < days[j].Date
+	})
+	return days
+}
+
+// Save はカレンダーを JSON ファイルに保存する。
+// 途中で落ちたり同時に読まれたりしても壊れたファイルが見えないよう、一時ファイルに書いてから置き換える
+func (c *Calendar) Save(path string) error {
+	b, err := json.Marshal(c.Days())
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadCalendar は Save で保存したカレンダーを読み込む
+func LoadCalendar(path string) (*Calendar, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var days []TradingCalendarDay
+	if err := json.Unmarshal(b, &days); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return NewCalendar(days)
+}
+
+// GetCalendar は from から to までを含む Calendar を返す。
+// cachePath に保存済みのカレンダーが範囲を満たしていればそれを使い、足りなければ不足分を API から取得して保存する。
+// キャッシュと要求範囲が離れている場合も間の期間を取得するので、保存されるカレンダーは常に連続している
+func (c *JQuantsClient) GetCalendar(ctx context.Context, cachePath string, from, to time.Time) (*Calendar, error) {
+	from, to = truncateDay(from), truncateDay(to)
+
+	var days []TradingCalendarDay
+	ranges := [][2]time.Time{{from, to}}
+	if cachePath != "" {
+		if cal, err := LoadCalendar(cachePath); err == nil {
+			if cal.Covers(from) && cal.Covers(to) {
+				return cal, nil
+			}
+			// 取得済みの範囲を失わないよう、キャッシュの前後に足りない期間だけを取得して繋げる
+			first, last := cal.Range()
+			ranges = ranges[:0]
+			if from.Before(first) {
+				ranges = append(ranges, [2]time.Time{from, first.AddDate(0, 0, -1)})
+			}
+			if to.After(last) {
+				ranges = append(ranges, [2]time.Time{last.AddDate(0, 0, 1), to})
+			}
+			days = cal.Days()
+		}
+	}
+
+	for _, r := range ranges {
+		fetched, err := c.GetTradingCalendarWithContext(ctx, GetTradingCalendarParams{
+			From: r[0].Format(dateLayout),
+			To:   r[1].Format(dateLayout),
+		})
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, fetched...)
+	}
+	cal, err := NewCalendar(days)
+	if err != nil {
+		return nil, err
+	}
+
+	if cachePath != "" {
+		if err := cal.Save(cachePath); err != nil {
+			log.Printf("[WARN] Failed to save trading calendar cache: %v", err)
+		}
+	}
+	return cal, nil
+}
@@ -0,0 +1,223 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 2023年末〜2024年始を模したテスト用カレンダー (半日立会日は判定確認のために入れている)
+var testCalendarDays = []TradingCalendarDay{
+	{Date: "2023-12-28", HolidayDivision: HolidayDivisionBusinessDay},
+	{Date: "2023-12-29", HolidayDivision: HolidayDivisionNonBusinessDay},
+	{Date: "2023-12-30", HolidayDivision: HolidayDivisionNonBusinessDay},
+	{Date: "2023-12-31", HolidayDivision: HolidayDivisionNonBusinessDay},
+	{Date: "2024-01-01", HolidayDivision: HolidayDivisionNonBusinessDay},
+	{Date: "2024-01-02", HolidayDivision: HolidayDivisionNonBusinessDay},
+	{Date: "2024-01-03", HolidayDivision: HolidayDivisionNonBusinessDay},
+	{Date: "2024-01-04", HolidayDivision: HolidayDivisionHalfDay},
+	{Date: "2024-01-05", HolidayDivision: HolidayDivisionBusinessDay},
+	{Date: "2024-01-06", HolidayDivision: HolidayDivisionNonBusinessDay},
+	{Date: "2024-01-07", HolidayDivision: HolidayDivisionNonBusinessDay},
+	{Date: "2024-01-08", HolidayDivision: HolidayDivisionNonBusinessDay},
+	{Date: "2024-01-09", HolidayDivision: HolidayDivisionBusinessDay},
+}
+
+func day(s string) time.Time {
+	t, err := ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCalendar(t *testing.T) {
+	cal, err := NewCalendar(testCalendarDays)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cal.IsTradingDay(day("2024-01-04")) || !cal.IsHalfDay(day("2024-01-04")) {
+		t.Error("Expected 2024-01-04 to be a half trading day")
+	}
+	if cal.IsTradingDay(day("2024-01-08")) {
+		t.Error("Expected 2024-01-08 (Coming of Age Day) to be a holiday")
+	}
+	// UTC で前日の夜でも JST の日付で判定する
+	if !cal.IsTradingDay(time.Date(2024, 1, 4, 16, 0, 0, 0, time.UTC)) {
+		t.Error("Expected 2024-01-05 JST to be a trading day")
+	}
+
+	if next, ok := cal.NextTradingDay(day("2023-12-28")); !ok || !next.Equal(day("2024-01-04")) {
+		t.Errorf("Unexpected next trading day: %v (ok=%v)", next, ok)
+	}
+	if prev, ok := cal.PrevTradingDay(day("2024-01-09")); !ok || !prev.Equal(day("2024-01-05")) {
+		t.Errorf("Unexpected prev trading day: %v (ok=%v)", prev, ok)
+	}
+	if _, ok := cal.NextTradingDay(day("2024-01-09")); ok {
+		t.Error("Expected no next trading day beyond the calendar range")
+	}
+
+	days := cal.TradingDaysBetween(day("2023-12-28"), day("2024-01-09"))
+	if len(days) != 4 {
+		t.Errorf("Expected 4 trading days, got %v", days)
+	}
+	if cal.Covers(day("2024-01-10")) {
+		t.Error("Expected 2024-01-10 to be out of range")
+	}
+}
+
+func TestGetCalendarUsesCache(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/markets/trading_calendar" || r.URL.Query().Get("from") != "2023-12-28" {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		w.Write([]byte(`{"trading_calendar":[
+			{"Date":"2023-12-28","HolidayDivision":"1"},
+			{"Date":"2023-12-29","HolidayDivision":"0"},
+			{"Date":"2024-01-04","HolidayDivision":"2"}
+		]}`))
+	}))
+	path := filepath.Join(t.TempDir(), "calendar.json")
+
+	for i := 0; i < 2; i++ {
+		cal, err := c.GetCalendar(context.Background(), path, day("2023-12-28"), day("2024-01-04"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cal.IsHalfDay(day("2024-01-04")) {
+			t.Error("Expected 2024-01-04 to be a half trading day")
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected the second call to be served from cache, got %d requests", requests.Load())
+	}
+}
+
+func TestStreamTradingCalendar(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/markets/trading_calendar" || q.Get("holidaydivision") != "1" || q.Get("from") != "2024-01-04" {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		switch q.Get("pagination_key") {
+		case "":
+			w.Write([]byte(`{"trading_calendar":[{"Date":"2024-01-04","HolidayDivision":"1"}],"pagination_key":"next"}`))
+		case "next":
+			w.Write([]byte(`{"trading_calendar":[{"Date":"2024-01-05","HolidayDivision":"1"}]}`))
+		default:
+			t.Errorf("Unexpected pagination key: %s", q.Get("pagination_key"))
+		}
+	}))
+
+	var pages [][]TradingCalendarDay
+	err := c.StreamTradingCalendar(context.Background(), GetTradingCalendarParams{HolidayDivision: HolidayDivisionBusinessDay, From: "2024-01-04"}, func(page []TradingCalendarDay) error {
+		pages = append(pages, page)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 || pages[0][0].Date != "2024-01-04" || pages[1][0].Date != "2024-01-05" {
+		t.Errorf("Unexpected pages: %+v", pages)
+	}
+}
+
+func TestCalendarSaveReplacesFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "calendar.json")
+	if err := os.WriteFile(path, []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cal, err := NewCalendar([]TradingCalendarDay{
+		{Date: "2024-01-04", HolidayDivision: HolidayDivisionHalfDay},
+		{Date: "2024-01-05", HolidayDivision: HolidayDivisionBusinessDay},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cal.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadCalendar(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Days(), cal.Days()) {
+		t.Errorf("Expected %+v, got %+v", cal.Days(), loaded.Days())
+	}
+	// 一時ファイルは残らない
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the calendar file, got %v", entries)
+	}
+}
+
+func TestGetCalendarFetchesGapBetweenCacheAndRequest(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		ranges []string
+	)
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+		mu.Lock()
+		ranges = append(ranges, from+".."+to)
+		mu.Unlock()
+
+		// 要求された期間の土日を休日、それ以外を営業日として返す
+		var days []TradingCalendarDay
+		for d := day(from); !d.After(day(to)); d = d.AddDate(0, 0, 1) {
+			div := HolidayDivisionBusinessDay
+			if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+				div = HolidayDivisionNonBusinessDay
+			}
+			days = append(days, TradingCalendarDay{Date: d.Format(dateLayout), HolidayDivision: div})
+		}
+		json.NewEncoder(w).Encode(tradingCalendarResponse{TradingCalendar: days})
+	}))
+	path := filepath.Join(t.TempDir(), "calendar.json")
+	ctx := context.Background()
+
+	if _, err := c.GetCalendar(ctx, path, day("2020-01-01"), day("2020-02-29")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetCalendar(ctx, path, day("2024-06-01"), day("2024-06-30")); err != nil {
+		t.Fatal(err)
+	}
+	cal, err := c.GetCalendar(ctx, path, day("2022-03-01"), day("2022-03-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"2020-01-01..2020-02-29", "2020-03-01..2024-06-30"}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("Expected fetched ranges %v, got %v", expected, ranges)
+	}
+	if !cal.IsTradingDay(day("2022-03-01")) {
+		t.Error("Expected 2022-03-01 to be a trading day")
+	}
+	if first, last := cal.Range(); !first.Equal(day("2020-01-01")) || !last.Equal(day("2024-06-30")) {
+		t.Errorf("Unexpected calendar range: %v..%v", first, last)
+	}
+}
@@ -2,35 +2,134 @@ package jquants
 
 import (
 	"Go-AutoTrade/config"
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 )
 
-// JQuantsClient は J-Quants API 利用のクライアントを表す
+// defaultBaseURL は J-Quants API のデフォルトのエンドポイント
+const defaultBaseURL = "https://api.jquants.com/v1"
+
+const (
+	// defaultExpiryMargin はトークンの期限のこれだけ前から期限切れとみなす
+	defaultExpiryMargin = 5 * time.Minute
+	// tokenRefresherRetryInterval は裏での再取得に失敗したときに次に試すまでの間隔
+	tokenRefresherRetryInterval = time.Minute
+	// tokenRefresherMinInterval は裏での再取得の最短の間隔。
+	// トークンの有効期間が expiryMargin 以下だと待ち時間が 0 以下になり、再取得を立て続けに繰り返してしまうため
+	tokenRefresherMinInterval = time.Minute
+	// tokenRefreshTimeout は呼び出し元から切り離して行うトークン再取得の打ち切り時間 (リトライを含む)
+	tokenRefreshTimeout = 2 * time.Minute
+)
+
+// JQuantsClient は J-Quants API 利用のクライアントを表す。
+// 複数の goroutine から同時に使ってよい。トークンのフィールドは mu で保護されているため、
+// API 呼び出しと並行して読む場合は Tokens() を使う
 type JQuantsClient struct {
-	IDToken        string
-	IDTokenExpiry  time.Time
-	RefreshToken   string
-	RefreshExp     time.Time
+	mu            sync.RWMutex
+	IDToken       string
+	IDTokenExpiry time.Time
+	RefreshToken  string
+	RefreshExp    time.Time
+
+	// refreshIssued と idTokenIssued はこのクライアントが各トークンを取得した時刻 (mu で保護)。
+	// ストアから読み込んだトークンではゼロ値
+	refreshIssued time.Time
+	idTokenIssued time.Time
+
+	baseURL     string
+	httpClient  *http.Client
+	clock       Clock
+	tokenStore  TokenStore
+	mailAddress string
+	password    string
+	retryPolicy RetryPolicy
+	limiter     *RateLimiter
+	plan        Plan // WithPlan か J_QUANTS_PLAN で指定された契約プラン。不明なら空
+	cache       *ResponseCache
+
+	// expiryMargin はトークンの期限のどれだけ前から期限切れ扱いにするか
+	expiryMargin time.Duration
+
+	// refreshGroup は同時に発生したトークン再取得を1回にまとめる
+	refreshGroup flightGroup
+
+	// sleep はリトライ待ちに使う。テストで差し替えられるようにフィールドにしている
+	sleep func(ctx context.Context, d time.Duration) error
 }
 
-// New はトークン管理を行い、IDトークンをセットしたクライアントを返す
-func New() (*JQuantsClient, error) {
-	t, err := loadTokens()
-	if err != nil {
-		log.Println("[INFO] tokens.json not found. Creating new.")
-		t = &tokenData{}
+// Clock は現在時刻を返すインターフェース。テストで時刻を差し替えるために使う
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Option は New に渡すクライアント設定
+type Option func(*JQuantsClient)
+
+// WithBaseURL は API のベースURLを差し替える (例: テスト用のローカルサーバ)
+func WithBaseURL(baseURL string) Option {
+	return func(c *JQuantsClient) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
 	}
+}
 
-	c := &JQuantsClient{
-		IDToken:       t.IDToken,
-		IDTokenExpiry: t.IDTokenExpiry,
-		RefreshToken:  t.RefreshToken,
-		RefreshExp:    t.RefreshTokenExpiry,
+// WithHTTPClient はリクエストに使う *http.Client を指定する。タイムアウトやプロキシはこちらで設定する
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *JQuantsClient) {
+		c.httpClient = hc
 	}
+}
+
+// WithClock はトークンの期限判定に使う時計を差し替える
+func WithClock(clock Clock) Option {
+	return func(c *JQuantsClient) {
+		c.clock = clock
+	}
+}
+
+// WithTokenStore はトークンの保存先を差し替える
+func WithTokenStore(s TokenStore) Option {
+	return func(c *JQuantsClient) {
+		c.tokenStore = s
+	}
+}
+
+// WithTokenExpiryMargin はトークンの期限のどれだけ前に取り直すかを指定する。
+// ページ取得の途中で期限が切れないよう、長めのページネーションを行う場合は大きめにする
+func WithTokenExpiryMargin(d time.Duration) Option {
+	return func(c *JQuantsClient) {
+		c.expiryMargin = d
+	}
+}
+
+// WithCredentials は認証に使うメールアドレスとパスワードを指定する。未指定なら環境変数の値を使う
+func WithCredentials(mail, pass string) Option {
+	return func(c *JQuantsClient) {
+		c.mailAddress = mail
+		c.password = pass
+	}
+}
+
+// New はトークン管理を行い、IDトークンをセットしたクライアントを返す
+func New(opts ...Option) (*JQuantsClient, error) {
+	return NewWithContext(context.Background(), opts...)
+}
+
+// NewWithContext は New の context 対応版。初回の認証リクエストは ctx でキャンセルできる
+func NewWithContext(ctx context.Context, opts ...Option) (*JQuantsClient, error) {
+	c := newClient(opts...)
 
 	// いったんensureToken() で必ずトークンが有効になるようにする
-	if err := c.ensureToken(); err != nil {
+	if err := c.ensureToken(ctx); err != nil {
 		return nil, err
 	}
 
@@ -38,44 +137,262 @@ func New() (*JQuantsClient, error) {
 	return c, nil
 }
 
+// newClient はオプションを適用し、保存済みトークンを読み込んだクライアントを返す (認証は行わない)
+func newClient(opts ...Option) *JQuantsClient {
+	c := &JQuantsClient{
+		baseURL:      defaultBaseURL,
+		httpClient:   &http.Client{},
+		clock:        systemClock{},
+		mailAddress:  config.GlobalConfig.JQuantsMailAddress,
+		password:     config.GlobalConfig.JQuantsPassword,
+		retryPolicy:  DefaultRetryPolicy,
+		expiryMargin: defaultExpiryMargin,
+		sleep:        sleepContext,
+	}
+	// J_QUANTS_PLAN が設定されていればそのプランの上限でリクエストを間引く
+	if plan := config.GlobalConfig.JQuantsPlan; plan != "" {
+		if p, err := ParsePlan(plan); err != nil {
+			log.Printf("[WARN] %v. Rate limiting disabled.", err)
+		} else {
+			c.limiter = NewRateLimiter(p.RateLimit())
+			c.plan = p
+		}
+	}
+	// J_QUANTS_CACHE_DIR が設定されていればレスポンスをディスクにキャッシュする
+	if dir := config.GlobalConfig.JQuantsCacheDir; dir != "" {
+		if cache, err := NewResponseCache(dir, DefaultCachePolicy); err != nil {
+			log.Printf("[WARN] %v. Response cache disabled.", err)
+		} else {
+			c.cache = cache
+		}
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.tokenStore == nil {
+		c.tokenStore = defaultTokenStore()
+	}
+
+	t, err := c.tokenStore.Load()
+	if err != nil {
+		log.Println("[INFO] Saved tokens not found. Creating new.")
+		t = &Tokens{}
+	}
+	c.IDToken = t.IDToken
+	c.IDTokenExpiry = t.IDTokenExpiry
+	c.RefreshToken = t.RefreshToken
+	c.RefreshExp = t.RefreshTokenExpiry
+
+	return c
+}
+
+// defaultTokenStore は環境変数の設定に応じたファイルの TokenStore を返す。
+// J_QUANTS_TOKEN_KEY が設定されていればトークンを暗号化して保存する
+func defaultTokenStore() TokenStore {
+	path := config.GlobalConfig.JQuantsTokenFile
+	if path == "" {
+		path = defaultTokenFilePath
+	}
+	if key := config.GlobalConfig.JQuantsTokenKey; key != "" {
+		s, err := NewEncryptedFileTokenStore(path, key)
+		if err == nil {
+			return s
+		}
+		// 暗号化を求められているので平文では保存せず、メモリ上だけで保持する
+		log.Printf("[ERROR] Failed to init encrypted token store, tokens will not be persisted: %v", err)
+		return NewMemoryTokenStore(nil)
+	}
+	return NewFileTokenStore(path)
+}
+
+// endpoint はベースURLにパスを連結したURLを返す
+func (c *JQuantsClient) endpoint(path string) string {
+	return c.baseURL + path
+}
+
+// endpointName はリクエストURLからベースURLのパス部分を除いた、エラー表示用のエンドポイント名を返す
+func (c *JQuantsClient) endpointName(u *url.URL) string {
+	if base, err := url.Parse(c.baseURL); err == nil {
+		if name := strings.TrimPrefix(u.Path, base.Path); name != u.Path {
+			return name
+		}
+	}
+	return u.Path
+}
+
+// Tokens は現在保持しているトークン情報のコピーを返す。
+// 他の goroutine が API を呼んでいる間はフィールドを直接読まずにこちらを使う
+func (c *JQuantsClient) Tokens() Tokens {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Tokens{
+		RefreshToken:       c.RefreshToken,
+		RefreshTokenExpiry: c.RefreshExp,
+		IDToken:            c.IDToken,
+		IDTokenExpiry:      c.IDTokenExpiry,
+	}
+}
+
+// tokensValid は ID トークンとリフレッシュトークンがどちらも有効かを返す
+func (c *JQuantsClient) tokensValid() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	now := c.clock.Now()
+	return c.RefreshToken != "" && !c.expiring(now, c.RefreshExp, c.refreshIssued) &&
+		c.IDToken != "" && !c.expiring(now, c.IDTokenExpiry, c.idTokenIssued)
+}
+
+// expiring は期限 exp のトークンを取り直すべきかを返す。issued はトークンを取得した時刻 (不明ならゼロ値)。
+// 有効期間が expiryMargin 以下のトークンでは毎回取り直すことになってしまうため、
+// マージンを有効期間の半分までに縮める
+func (c *JQuantsClient) expiring(now, exp, issued time.Time) bool {
+	return isExpiringOrExpired(now, exp, c.marginFor(exp, issued))
+}
+
+// marginFor は期限 exp のトークンに適用するマージンを返す
+func (c *JQuantsClient) marginFor(exp, issued time.Time) time.Duration {
+	margin := c.expiryMargin
+	if !issued.IsZero() {
+		if half := exp.Sub(issued) / 2; half < margin {
+			margin = half
+		}
+	}
+	return margin
+}
+
 // ensureToken はIDトークンが期限切れであれば再取得する、
-// あるいはRefreshトークンも期限切れであれば再発行するなどを担うメソッド
-func (c *JQuantsClient) ensureToken() error {
+// あるいはRefreshトークンも期限切れであれば再発行するなどを担うメソッド。
+// 複数の goroutine から同時に呼ばれても、実際の再取得は1回にまとめられる
+func (c *JQuantsClient) ensureToken(ctx context.Context) error {
+	if c.tokensValid() {
+		return nil
+	}
+	return c.refreshGroup.Do(ctx, tokenRefreshTimeout, c.refreshTokens)
+}
+
+// refreshTokens は無効になっているトークンを取り直して保存する。ensureToken からのみ呼ぶ
+func (c *JQuantsClient) refreshTokens(ctx context.Context) error {
+	t := c.Tokens()
+	c.mu.RLock()
+	refreshIssued, idTokenIssued := c.refreshIssued, c.idTokenIssued
+	c.mu.RUnlock()
+	now := c.clock.Now()
+	changed := false
+
 	// RefreshToken がない or 期限切れの場合
-	if c.RefreshToken == "" || isExpiringOrExpired(c.RefreshExp, 0) {
+	if t.RefreshToken == "" || c.expiring(now, t.RefreshTokenExpiry, refreshIssued) {
 		log.Println("[INFO] Refresh token invalid, acquiring new.")
-		mail := config.GlobalConfig.JQuantsMailAddress
-		pass := config.GlobalConfig.JQuantsPassword
-
-		rt, rtExp, err := getRefreshTokenByCredentials(mail, pass)
+		rt, rtExp, err := c.getRefreshTokenByCredentials(ctx, c.mailAddress, c.password)
 		if err != nil {
 			return err
 		}
-		c.RefreshToken = rt
-		c.RefreshExp = rtExp
+		t.RefreshToken = rt
+		t.RefreshTokenExpiry = rtExp
+		refreshIssued = now
+		// リフレッシュトークンが変わったので ID トークンも取り直す
+		t.IDToken = ""
+		changed = true
 		log.Println("[INFO] Acquired new refresh token.")
 	}
 
 	// IDToken がない or 期限切れの場合
-	if c.IDToken == "" || isExpiringOrExpired(c.IDTokenExpiry, 0) {
+	if t.IDToken == "" || c.expiring(now, t.IDTokenExpiry, idTokenIssued) {
 		log.Println("[INFO] ID token invalid, acquiring new.")
-		it, itExp, err := getIDTokenByRefreshToken(c.RefreshToken)
+		it, itExp, err := c.getIDTokenByRefreshToken(ctx, t.RefreshToken)
 		if err != nil {
+			if changed {
+				// 取得済みのリフレッシュトークンは無駄にしない
+				c.setTokens(t, refreshIssued, idTokenIssued)
+			}
 			return err
 		}
-		c.IDToken = it
-		c.IDTokenExpiry = itExp
+		t.IDToken = it
+		t.IDTokenExpiry = itExp
+		idTokenIssued = now
+		changed = true
 		log.Println("[INFO] Acquired new ID token.")
 	}
 
+	if !changed {
+		return nil
+	}
+
 	// 最新のトークン情報を保存しておく
-	t := &tokenData{
-		RefreshToken:       c.RefreshToken,
-		RefreshTokenExpiry: c.RefreshExp,
-		IDToken:            c.IDToken,
-		IDTokenExpiry:      c.IDTokenExpiry,
+	c.setTokens(t, refreshIssued, idTokenIssued)
+	if err := c.tokenStore.Save(&t); err != nil {
+		log.Printf("[ERROR] Failed to save tokens: %v\n", err)
+	}
+
+	return nil
+}
+
+func (c *JQuantsClient) setTokens(t Tokens, refreshIssued, idTokenIssued time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.RefreshToken = t.RefreshToken
+	c.RefreshExp = t.RefreshTokenExpiry
+	c.IDToken = t.IDToken
+	c.IDTokenExpiry = t.IDTokenExpiry
+	c.refreshIssued = refreshIssued
+	c.idTokenIssued = idTokenIssued
+}
+
+// forceRefresh は期限に関係なくIDトークンを取り直す。
+// staleIDToken は拒否されたトークンで、既に別の goroutine が取り直していれば何もしない。
+// リフレッシュトークンも拒否された場合はメールアドレスとパスワードで再認証する
+func (c *JQuantsClient) forceRefresh(ctx context.Context, staleIDToken string) error {
+	c.mu.Lock()
+	if c.IDToken == staleIDToken {
+		c.IDToken = ""
 	}
-	saveTokens(t)
+	staleRefreshToken := c.RefreshToken
+	c.mu.Unlock()
 
+	err := c.ensureToken(ctx)
+	if err == nil || !hasStatus(err, http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden) {
+		return err
+	}
+
+	log.Println("[WARN] Refresh token rejected. Re-authenticating with credentials.")
+	c.mu.Lock()
+	if c.RefreshToken == staleRefreshToken {
+		c.RefreshToken = ""
+	}
+	c.mu.Unlock()
+	if retryErr := c.ensureToken(ctx); retryErr != nil {
+		return errors.Join(retryErr, err)
+	}
 	return nil
 }
+
+// RunTokenRefresher はトークンの期限が近づくたびに裏で取り直し続ける。ctx がキャンセルされるまで戻らない。
+// 長時間動かすプロセスで、API 呼び出しの途中に再認証が挟まらないようにするために使う:
+//
+//	go c.RunTokenRefresher(ctx)
+func (c *JQuantsClient) RunTokenRefresher(ctx context.Context) error {
+	for {
+		c.mu.RLock()
+		next := c.IDTokenExpiry.Add(-c.marginFor(c.IDTokenExpiry, c.idTokenIssued))
+		if rt := c.RefreshExp.Add(-c.marginFor(c.RefreshExp, c.refreshIssued)); rt.Before(next) {
+			next = rt
+		}
+		c.mu.RUnlock()
+		wait := next.Sub(c.clock.Now())
+		if wait < tokenRefresherMinInterval {
+			wait = tokenRefresherMinInterval
+		}
+		if err := c.sleep(ctx, wait); err != nil {
+			return err
+		}
+
+		if err := c.ensureToken(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("[WARN] Background token refresh failed: %v. Retrying in %s", err, tokenRefresherRetryInterval)
+			if err := c.sleep(ctx, tokenRefresherRetryInterval); err != nil {
+				return err
+			}
+		}
+	}
+}
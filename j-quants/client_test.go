@@ -0,0 +1,167 @@
+package jquants
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fixedClock は常に同じ時刻を返すテスト用の Clock
+type fixedClock struct {
+	now time.Time
+}
+
+func (f fixedClock) Now() time.Time { return f.now }
+
+func TestNewWithOptions(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token/auth_user", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"refreshToken": "local_rt"})
+	})
+	mux.HandleFunc("/token/auth_refresh", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("refreshtoken") != "local_rt" {
+			t.Errorf("unexpected refresh token: %s", r.URL.Query().Get("refreshtoken"))
+		}
+		json.NewEncoder(w).Encode(map[string]string{"idToken": "local_it"})
+	})
+	mux.HandleFunc("/prices/daily_quotes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer local_it" {
+			t.Errorf("unexpected Authorization header: %s", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"daily_quotes":[{"Date":"2024-01-04","Code":"72030","Close":2500}]}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	now := time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryTokenStore(nil)
+	c, err := New(
+		WithBaseURL(srv.URL+"/"),
+		WithHTTPClient(srv.Client()),
+		WithClock(fixedClock{now: now}),
+		WithTokenStore(store),
+		WithCredentials("dummy@mail", "dummy_pass"),
+	)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if !c.IDTokenExpiry.Equal(now.Add(24 * time.Hour)) {
+		t.Errorf("Expected ID token expiry from injected clock, got: %v", c.IDTokenExpiry)
+	}
+	if saved, err := store.Load(); err != nil || saved.IDToken != "local_it" {
+		t.Error("Expected tokens to be saved to the injected store")
+	}
+
+	quotes, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"})
+	if err != nil {
+		t.Fatalf("GetDailyQuotes failed: %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].Close != Float(2500) {
+		t.Errorf("Unexpected quotes: %+v", quotes)
+	}
+}
+
+// newServerClient はテストサーバに向けた、有効なトークンを持つクライアントを返す
+func newServerClient(t *testing.T, handler http.Handler, opts ...Option) *JQuantsClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	store := NewMemoryTokenStore(&Tokens{
+		RefreshToken:       "test_rt",
+		RefreshTokenExpiry: time.Now().Add(7 * 24 * time.Hour),
+		IDToken:            "test_it",
+		IDTokenExpiry:      time.Now().Add(24 * time.Hour),
+	})
+	opts = append([]Option{
+		WithBaseURL(srv.URL),
+		WithHTTPClient(srv.Client()),
+		WithTokenStore(store),
+	}, opts...)
+	return newClient(opts...)
+}
+
+// authTestServer は 401 からの復旧を確認するための J-Quants もどき
+type authTestServer struct {
+	mu               sync.Mutex
+	validIDToken     string
+	rejectRefresh    bool
+	authUserCalls    int
+	authRefreshCalls int
+	dataCalls        int
+}
+
+func (s *authTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/token/auth_user":
+		s.authUserCalls++
+		json.NewEncoder(w).Encode(map[string]string{"refreshToken": "new_rt"})
+	case "/token/auth_refresh":
+		s.authRefreshCalls++
+		if s.rejectRefresh && r.URL.Query().Get("refreshtoken") != "new_rt" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"'refreshtoken' is invalid."}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"idToken": "new_it"})
+	default:
+		s.dataCalls++
+		if r.Header.Get("Authorization") != "Bearer "+s.validIDToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"The incoming token is invalid or expired."}`))
+			return
+		}
+		w.Write([]byte(`{"daily_quotes":[{"Date":"2024-01-04","Code":"72030"}]}`))
+	}
+}
+
+func TestRecoverFrom401(t *testing.T) {
+	t.Parallel()
+
+	srv := &authTestServer{validIDToken: "new_it"}
+	c := newServerClient(t, srv)
+
+	if _, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"}); err != nil {
+		t.Fatalf("Expected 401 to be recovered, got: %v", err)
+	}
+	if srv.dataCalls != 2 || srv.authRefreshCalls != 1 || srv.authUserCalls != 0 {
+		t.Errorf("Unexpected calls: data=%d refresh=%d user=%d", srv.dataCalls, srv.authRefreshCalls, srv.authUserCalls)
+	}
+}
+
+func TestRecoverFrom401WithRejectedRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	srv := &authTestServer{validIDToken: "new_it", rejectRefresh: true}
+	c := newServerClient(t, srv)
+
+	if _, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"}); err != nil {
+		t.Fatalf("Expected re-authentication to succeed, got: %v", err)
+	}
+	if srv.authUserCalls != 1 || c.RefreshToken != "new_rt" {
+		t.Errorf("Expected re-authentication with credentials, got user=%d rt=%s", srv.authUserCalls, c.RefreshToken)
+	}
+}
+
+func TestRecoverFrom401OnlyOnce(t *testing.T) {
+	t.Parallel()
+
+	// どのトークンでも 401 を返すサーバ
+	srv := &authTestServer{validIDToken: "never_valid"}
+	c := newServerClient(t, srv)
+
+	if _, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"}); err == nil {
+		t.Fatal("Expected error when the server keeps returning 401")
+	}
+	if srv.dataCalls != 2 {
+		t.Errorf("Expected the request to be replayed only once, got %d calls", srv.dataCalls)
+	}
+}
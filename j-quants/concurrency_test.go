@@ -0,0 +1,124 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const concurrentCallers = 20
+
+// runConcurrently は concurrentCallers 個の goroutine で GetDailyQuotes を同時に呼ぶ
+func runConcurrently(t *testing.T, c *JQuantsClient) {
+	t.Helper()
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < concurrentCallers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if _, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+// refreshCountingHandler は auth_refresh の呼び出し回数を数え、validIDToken 以外を 401 にするハンドラ
+func refreshCountingHandler(refreshCalls *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token/auth_refresh":
+			refreshCalls.Add(1)
+			// 同時に待っている呼び出しが確実に重なるよう少し遅らせる
+			time.Sleep(20 * time.Millisecond)
+			json.NewEncoder(w).Encode(map[string]string{"idToken": "new_it"})
+		default:
+			if r.Header.Get("Authorization") != "Bearer new_it" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"daily_quotes":[{"Date":"2024-01-04","Code":"72030"}]}`))
+		}
+	})
+}
+
+func TestConcurrentCallersShareOneRefresh(t *testing.T) {
+	t.Parallel()
+
+	var refreshCalls atomic.Int32
+	c := newServerClient(t, refreshCountingHandler(&refreshCalls))
+	// ID トークンだけ期限切れにしておく
+	c.IDTokenExpiry = time.Now().Add(-time.Minute)
+
+	runConcurrently(t, c)
+
+	if n := refreshCalls.Load(); n != 1 {
+		t.Errorf("Expected exactly 1 auth_refresh call, got %d", n)
+	}
+	if tokens := c.Tokens(); tokens.IDToken != "new_it" {
+		t.Errorf("Expected refreshed ID token, got %s", tokens.IDToken)
+	}
+}
+
+func TestConcurrent401SharesOneRefresh(t *testing.T) {
+	t.Parallel()
+
+	var refreshCalls atomic.Int32
+	// 期限内だがサーバ側で失効させられた ID トークンを持っている状態
+	c := newServerClient(t, refreshCountingHandler(&refreshCalls))
+
+	runConcurrently(t, c)
+
+	if n := refreshCalls.Load(); n != 1 {
+		t.Errorf("Expected exactly 1 auth_refresh call, got %d", n)
+	}
+}
+
+func TestCanceledCallerDoesNotFailSharedRefresh(t *testing.T) {
+	t.Parallel()
+
+	var refreshCalls atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshCalls.Add(1)
+		close(started)
+		<-release
+		json.NewEncoder(w).Encode(map[string]string{"idToken": "new_it"})
+	}))
+	c.IDTokenExpiry = time.Now().Add(-time.Minute)
+
+	// 最初に再取得を始めた呼び出し元が、再取得の途中でキャンセルする
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() { leader <- c.ensureToken(ctx) }()
+	<-started
+
+	follower := make(chan error, 1)
+	go func() { follower <- c.ensureToken(context.Background()) }()
+	cancel()
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the canceled caller to return context.Canceled, got: %v", err)
+	}
+
+	// 一緒に待っていた呼び出し元は、続いている再取得の結果を受け取る
+	close(release)
+	if err := <-follower; err != nil {
+		t.Errorf("Expected the other caller to succeed, got: %v", err)
+	}
+	if n := refreshCalls.Load(); n != 1 {
+		t.Errorf("Expected exactly 1 auth_refresh call, got %d", n)
+	}
+	if tokens := c.Tokens(); tokens.IDToken != "new_it" {
+		t.Errorf("Expected refreshed ID token, got %s", tokens.IDToken)
+	}
+}
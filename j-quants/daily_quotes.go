@@ -1,27 +1,65 @@
 package jquants
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
 )
 
-// DailyQuote は /prices/daily_quotes の1行分
+// DailyQuote は /prices/daily_quotes の1行分。
+// 売買停止などで約定が無かった日は四本値・出来高が null で返ってくるので、価格は NullFloat64 で持つ。
+// Morning* / Afternoon* は前場・後場の値で、Premium プランのときだけ返ってくる
 type DailyQuote struct {
-	Date              string  `json:"Date"`
-	Code              string  `json:"Code"`
-	Open              float64 `json:"Open"`
-	High              float64 `json:"High"`
-	Low               float64 `json:"Low"`
-	Close             float64 `json:"Close"`
-	Volume            float64 `json:"Volume"`
-	TurnoverValue     float64 `json:"TurnoverValue"`
-	AdjustmentOpen    float64 `json:"AdjustmentOpen"`
-	AdjustmentHigh    float64 `json:"AdjustmentHigh"`
-	AdjustmentLow     float64 `json:"AdjustmentLow"`
-	AdjustmentClose   float64 `json:"AdjustmentClose"`
-	AdjustmentVolume  float64 `json:"AdjustmentVolume"`
-	// ... etc. 必要に応じて
+	Date             Date        `json:"Date"`
+	Code             string      `json:"Code"`
+	Open             NullFloat64 `json:"Open"`
+	High             NullFloat64 `json:"High"`
+	Low              NullFloat64 `json:"Low"`
+	Close            NullFloat64 `json:"Close"`
+	UpperLimit       NullBool    `json:"UpperLimit"` // ストップ高
+	LowerLimit       NullBool    `json:"LowerLimit"` // ストップ安
+	Volume           NullFloat64 `json:"Volume"`
+	TurnoverValue    NullFloat64 `json:"TurnoverValue"`
+	AdjustmentFactor NullFloat64 `json:"AdjustmentFactor"` // 株式分割などの調整係数。権利落ち日以外は 1。欠けていることがある
+	AdjustmentOpen   NullFloat64 `json:"AdjustmentOpen"`
+	AdjustmentHigh   NullFloat64 `json:"AdjustmentHigh"`
+	AdjustmentLow    NullFloat64 `json:"AdjustmentLow"`
+	AdjustmentClose  NullFloat64 `json:"AdjustmentClose"`
+	AdjustmentVolume NullFloat64 `json:"AdjustmentVolume"`
+
+	MorningOpen             NullFloat64 `json:"MorningOpen"`
+	MorningHigh             NullFloat64 `json:"MorningHigh"`
+	MorningLow              NullFloat64 `json:"MorningLow"`
+	MorningClose            NullFloat64 `json:"MorningClose"`
+	MorningUpperLimit       NullBool    `json:"MorningUpperLimit"`
+	MorningLowerLimit       NullBool    `json:"MorningLowerLimit"`
+	MorningVolume           NullFloat64 `json:"MorningVolume"`
+	MorningTurnoverValue    NullFloat64 `json:"MorningTurnoverValue"`
+	MorningAdjustmentOpen   NullFloat64 `json:"MorningAdjustmentOpen"`
+	MorningAdjustmentHigh   NullFloat64 `json:"MorningAdjustmentHigh"`
+	MorningAdjustmentLow    NullFloat64 `json:"MorningAdjustmentLow"`
+	MorningAdjustmentClose  NullFloat64 `json:"MorningAdjustmentClose"`
+	MorningAdjustmentVolume NullFloat64 `json:"MorningAdjustmentVolume"`
+
+	AfternoonOpen             NullFloat64 `json:"AfternoonOpen"`
+	AfternoonHigh             NullFloat64 `json:"AfternoonHigh"`
+	AfternoonLow              NullFloat64 `json:"AfternoonLow"`
+	AfternoonClose            NullFloat64 `json:"AfternoonClose"`
+	AfternoonUpperLimit       NullBool    `json:"AfternoonUpperLimit"`
+	AfternoonLowerLimit       NullBool    `json:"AfternoonLowerLimit"`
+	AfternoonVolume           NullFloat64 `json:"AfternoonVolume"`
+	AfternoonTurnoverValue    NullFloat64 `json:"AfternoonTurnoverValue"`
+	AfternoonAdjustmentOpen   NullFloat64 `json:"AfternoonAdjustmentOpen"`
+	AfternoonAdjustmentHigh   NullFloat64 `json:"AfternoonAdjustmentHigh"`
+	AfternoonAdjustmentLow    NullFloat64 `json:"AfternoonAdjustmentLow"`
+	AfternoonAdjustmentClose  NullFloat64 `json:"AfternoonAdjustmentClose"`
+	AfternoonAdjustmentVolume NullFloat64 `json:"AfternoonAdjustmentVolume"`
+}
+
+// Halted は約定が無かった日 (終値が null) かどうかを返す。リターンの計算からは除外すること
+func (q DailyQuote) Halted() bool {
+	return !q.Close.Valid
 }
 
 // dailyQuotesResponse : JSON全体を受け取るための構造
@@ -40,7 +78,22 @@ type GetDailyQuotesParams struct {
 
 // GetDailyQuotes は /prices/daily_quotes を全ページ取得し、[]DailyQuote を返す
 func (c *JQuantsClient) GetDailyQuotes(params GetDailyQuotesParams) ([]DailyQuote, error) {
-	baseURL := "https://api.jquants.com/v1/prices/daily_quotes"
+	return c.GetDailyQuotesWithContext(context.Background(), params)
+}
+
+// GetDailyQuotesWithContext は GetDailyQuotes の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetDailyQuotesWithContext(ctx context.Context, params GetDailyQuotesParams) ([]DailyQuote, error) {
+	// ここで pagination.go の共通関数を呼び出す
+	return DoPaginatedGetWithContext[DailyQuote](ctx, c, c.endpoint("/prices/daily_quotes"), params.values(), extractDailyQuotes)
+}
+
+// StreamDailyQuotes は /prices/daily_quotes を1ページずつ handle に渡す。全件をメモリに溜めない
+func (c *JQuantsClient) StreamDailyQuotes(ctx context.Context, params GetDailyQuotesParams, handle PageHandler[DailyQuote]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/prices/daily_quotes"), params.values(), extractDailyQuotes, handle)
+}
+
+func (params GetDailyQuotesParams) values() url.Values {
 	q := url.Values{}
 
 	if params.Code != "" {
@@ -55,15 +108,13 @@ func (c *JQuantsClient) GetDailyQuotes(params GetDailyQuotesParams) ([]DailyQuot
 	if params.Date != "" {
 		q.Set("date", params.Date)
 	}
+	return q
+}
 
-	extractor := func(respBytes []byte) ([]DailyQuote, string, error) {
-		var r dailyQuotesResponse
-		if err := json.Unmarshal(respBytes, &r); err != nil {
-			return nil, "", fmt.Errorf("failed to unmarshal daily_quotes: %w", err)
-		}
-		return r.DailyQuotes, r.PaginationKey, nil
+func extractDailyQuotes(respBytes []byte) ([]DailyQuote, string, error) {
+	var r dailyQuotesResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal daily_quotes: %w", err)
 	}
-
-	// ここで pagination.go の共通関数を呼び出す
-	return DoPaginatedGet[DailyQuote](c, baseURL, q, extractor)
+	return r.DailyQuotes, r.PaginationKey, nil
 }
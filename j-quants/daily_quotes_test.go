@@ -0,0 +1,98 @@
+package jquants
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDailyQuotesFromRecordedJSON(t *testing.T) {
+	t.Parallel()
+
+	body, err := os.ReadFile("testdata/daily_quotes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	quotes, err := c.GetDailyQuotes(GetDailyQuotesParams{Date: "2024-03-28"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 3 {
+		t.Fatalf("Expected 3 quotes, got %d", len(quotes))
+	}
+
+	// Premium プランの前場・後場の値
+	toyota := quotes[0]
+	if !toyota.Date.Equal(day("2024-03-27")) || toyota.Close != Float(3868) || toyota.Halted() {
+		t.Errorf("Unexpected quote: %+v", toyota)
+	}
+	if toyota.MorningClose != Float(3861) || toyota.AfternoonVolume != Float(13263900) || toyota.UpperLimit.Bool {
+		t.Errorf("Unexpected session values: %+v", toyota)
+	}
+
+	// 売買停止の日は 0 ではなく値なしになる
+	halted := quotes[1]
+	if !halted.Halted() || halted.Open.Valid || halted.Volume.Valid || halted.MorningClose.Valid {
+		t.Errorf("Expected null prices for a halted day, got: %+v", halted)
+	}
+
+	// Standard プラン相当 (前場・後場なし) のストップ高と分割
+	split := quotes[2]
+	if !split.UpperLimit.Valid || !split.UpperLimit.Bool || split.LowerLimit.Bool || split.AdjustmentFactor != Float(0.2) {
+		t.Errorf("Unexpected limit flags or factor: %+v", split)
+	}
+	if split.MorningOpen.Valid {
+		t.Error("Expected missing session fields to be invalid")
+	}
+}
+
+func TestDailyQuoteMissingValues(t *testing.T) {
+	var q DailyQuote
+	if err := json.Unmarshal([]byte(`{"Date":"-","Code":"72030","AdjustmentFactor":null}`), &q); err != nil {
+		t.Fatal(err)
+	}
+	// 欠けた調整係数は 0 ではなく値なしになり、"-" の日付はゼロ値になる
+	if q.AdjustmentFactor.Valid || !q.Date.IsZero() {
+		t.Errorf("Expected missing factor and date, got %+v", q)
+	}
+
+	// 値なしの調整係数は系列に取り込むときに補われる
+	s, err := NewPriceSeries([]DailyQuote{
+		{Date: DateOf(day("2024-01-04")), Code: "72030", Close: Float(2000), AdjustmentFactor: Float(1)},
+		{Date: DateOf(day("2024-01-05")), Code: "72030", Close: Float(2020)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Factors(); !approxSlice(got, []float64{1, 1}) || len(s.CorporateActions()) != 0 {
+		t.Errorf("Expected a missing factor to default to 1, got %v", got)
+	}
+}
+
+func TestDailyQuoteJSONRoundTrip(t *testing.T) {
+	body, err := os.ReadFile("testdata/daily_quotes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	quotes, _, err := extractDailyQuotes(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := json.Marshal(quotes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []DailyQuote
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(quotes, decoded) {
+		t.Errorf("Round trip changed the quotes:\n%+v\n%+v", quotes, decoded)
+	}
+}
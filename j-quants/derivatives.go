@@ -0,0 +1,357 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+)
+
+// PutCall はオプションのプット・コール区分
+type PutCall string
+
+const (
+	Put  PutCall = "1"
+	Call PutCall = "2"
+)
+
+func (p PutCall) String() string {
+	switch p {
+	case Put:
+		return "Put"
+	case Call:
+		return "Call"
+	}
+	return string(p)
+}
+
+// DerivativeQuote は先物・オプション共通の日次四本値と建玉。
+// 取引の無いセッションの値は "" で返ってくるので NullFloat64 で持つ
+type DerivativeQuote struct {
+	Date                       string `json:"Date"`
+	Code                       string `json:"Code"`
+	DerivativesProductCategory string `json:"DerivativesProductCategory"` // 例: "NK225F", "NK225E"
+	ContractMonth              string `json:"ContractMonth"`              // 限月 (例: "2024-03")
+
+	WholeDayOpen        NullFloat64 `json:"WholeDayOpen"`
+	WholeDayHigh        NullFloat64 `json:"WholeDayHigh"`
+	WholeDayLow         NullFloat64 `json:"WholeDayLow"`
+	WholeDayClose       NullFloat64 `json:"WholeDayClose"`
+	MorningSessionOpen  NullFloat64 `json:"MorningSessionOpen"`
+	MorningSessionHigh  NullFloat64 `json:"MorningSessionHigh"`
+	MorningSessionLow   NullFloat64 `json:"MorningSessionLow"`
+	MorningSessionClose NullFloat64 `json:"MorningSessionClose"`
+	NightSessionOpen    NullFloat64 `json:"NightSessionOpen"`
+	NightSessionHigh    NullFloat64 `json:"NightSessionHigh"`
+	NightSessionLow     NullFloat64 `json:"NightSessionLow"`
+	NightSessionClose   NullFloat64 `json:"NightSessionClose"`
+	DaySessionOpen      NullFloat64 `json:"DaySessionOpen"`
+	DaySessionHigh      NullFloat64 `json:"DaySessionHigh"`
+	DaySessionLow       NullFloat64 `json:"DaySessionLow"`
+	DaySessionClose     NullFloat64 `json:"DaySessionClose"`
+
+	Volume            NullFloat64 `json:"Volume"`
+	VolumeOnlyAuction NullFloat64 `json:"Volume(OnlyAuction)"`
+	OpenInterest      NullFloat64 `json:"OpenInterest"`
+	TurnoverValue     NullFloat64 `json:"TurnoverValue"`
+	SettlementPrice   NullFloat64 `json:"SettlementPrice"`
+
+	EmergencyMarginTriggerDivision string `json:"EmergencyMarginTriggerDivision"` // 001: 緊急取引証拠金発動時, 002: 清算価格算出時 (通常の値)
+	LastTradingDay                 string `json:"LastTradingDay"`
+	SpecialQuotationDay            string `json:"SpecialQuotationDay"`
+	CentralContractMonthFlag       string `json:"CentralContractMonthFlag"` // 1: 中心限月
+}
+
+// IsSettlement は清算価格算出時 (EmergencyMarginTriggerDivision が 002) の値かどうかを返す。
+// 緊急取引証拠金が発動した日は同じ銘柄に 001 の行も返ってくるので、通常はこちらを使う
+func (q DerivativeQuote) IsSettlement() bool {
+	return q.EmergencyMarginTriggerDivision == "002"
+}
+
+// IsCentralContractMonth は中心限月かどうかを返す
+func (q DerivativeQuote) IsCentralContractMonth() bool {
+	return q.CentralContractMonthFlag == "1"
+}
+
+// FuturesQuote は /derivatives/futures の1銘柄・1日分
+type FuturesQuote struct {
+	DerivativeQuote
+}
+
+// OptionQuote は /derivatives/options, /option/index_option の1銘柄・1日分。
+// /option/index_option (日経225オプション) では DerivativesProductCategory や前場の値は空になる
+type OptionQuote struct {
+	DerivativeQuote
+	PutCallDivision   PutCall     `json:"PutCallDivision"`
+	StrikePrice       NullFloat64 `json:"StrikePrice"`
+	TheoreticalPrice  NullFloat64 `json:"TheoreticalPrice"`
+	BaseVolatility    NullFloat64 `json:"BaseVolatility"`
+	UnderlyingPrice   NullFloat64 `json:"UnderlyingPrice"`
+	ImpliedVolatility NullFloat64 `json:"ImpliedVolatility"`
+	InterestRate      NullFloat64 `json:"InterestRate"`
+	UnderlyingSSO     string      `json:"UnderlyingSSO"` // 有価証券オプションの原資産コード。それ以外は "-"
+}
+
+// OptionChainRow はオプションチェーンの1行 (同じ権利行使価格のコールとプット)。片方しか無い場合もある
+type OptionChainRow struct {
+	StrikePrice float64
+	Call        *OptionQuote
+	Put         *OptionQuote
+}
+
+// OptionChain は1日・1限月・1原資産分のオプションチェーン
+type OptionChain struct {
+	Date                       string
+	DerivativesProductCategory string
+	UnderlyingSSO              string
+	ContractMonth              string
+	UnderlyingPrice            NullFloat64
+	Rows                       []OptionChainRow // 権利行使価格の昇順
+}
+
+// AtTheMoney は原資産価格に最も近い権利行使価格の行を返す。原資産価格が無ければ false
+func (ch OptionChain) AtTheMoney() (OptionChainRow, bool) {
+	if !ch.UnderlyingPrice.Valid || len(ch.Rows) == 0 {
+		return OptionChainRow{}, false
+	}
+	best := ch.Rows[0]
+	for _, row := range ch.Rows[1:] {
+		if math.Abs(row.StrikePrice-ch.UnderlyingPrice.Float64) < math.Abs(best.StrikePrice-ch.UnderlyingPrice.Float64) {
+			best = row
+		}
+	}
+	return best, true
+}
+
+// BuildOptionChains は日付・商品区分・原資産・限月ごとにオプションを束ね、権利行使価格で並べたチェーンを返す。
+// チェーンは日付, 商品区分, 原資産, 限月の昇順。権利行使価格の無いレコードは無視する。
+// 緊急取引証拠金の発動日に同じ銘柄の行が複数ある場合は、清算価格算出時 (002) の行を使う
+func BuildOptionChains(quotes []OptionQuote) []OptionChain {
+	type chainKey struct {
+		date, category, underlying, month string
+	}
+	chains := make(map[chainKey]*OptionChain)
+	rows := make(map[chainKey]map[float64]*OptionChainRow)
+
+	for i := range quotes {
+		q := &quotes[i]
+		if !q.StrikePrice.Valid {
+			continue
+		}
+		key := chainKey{q.Date, q.DerivativesProductCategory, q.UnderlyingSSO, q.ContractMonth}
+		ch, ok := chains[key]
+		if !ok {
+			ch = &OptionChain{
+				Date:                       q.Date,
+				DerivativesProductCategory: q.DerivativesProductCategory,
+				UnderlyingSSO:              q.UnderlyingSSO,
+				ContractMonth:              q.ContractMonth,
+			}
+			chains[key] = ch
+			rows[key] = make(map[float64]*OptionChainRow)
+		}
+		if !ch.UnderlyingPrice.Valid || (q.IsSettlement() && q.UnderlyingPrice.Valid) {
+			ch.UnderlyingPrice = q.UnderlyingPrice
+		}
+
+		row, ok := rows[key][q.StrikePrice.Float64]
+		if !ok {
+			row = &OptionChainRow{StrikePrice: q.StrikePrice.Float64}
+			rows[key][q.StrikePrice.Float64] = row
+		}
+		switch q.PutCallDivision {
+		case Call:
+			row.Call = preferSettlement(row.Call, q)
+		case Put:
+			row.Put = preferSettlement(row.Put, q)
+		}
+	}
+
+	keys := make([]chainKey, 0, len(chains))
+	for k := range chains {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.date != b.date {
+			return a.date < b.date
+		}
+		if a.category != b.category {
+			return a.category < b.category
+		}
+		if a.underlying != b.underlying {
+			return a.underlying < b.underlying
+		}
+		return a.month < b.month
+	})
+
+	result := make([]OptionChain, 0, len(keys))
+	for _, k := range keys {
+		ch := chains[k]
+		for _, row := range rows[k] {
+			ch.Rows = append(ch.Rows, *row)
+		}
+		sort.Slice(ch.Rows, func(i, j int) bool { return ch.Rows[i].StrikePrice < ch.Rows[j].StrikePrice })
+		result = append(result, *ch)
+	}
+	return result
+}
+
+// futuresResponse : JSON全体を受け取るための構造
+type futuresResponse struct {
+	Futures       []FuturesQuote `json:"futures"`
+	PaginationKey string         `json:"pagination_key"`
+}
+
+// optionsResponse : JSON全体を受け取るための構造
+type optionsResponse struct {
+	Options       []OptionQuote `json:"options"`
+	PaginationKey string        `json:"pagination_key"`
+}
+
+// indexOptionResponse : JSON全体を受け取るための構造
+type indexOptionResponse struct {
+	IndexOption   []OptionQuote `json:"index_option"`
+	PaginationKey string        `json:"pagination_key"`
+}
+
+// GetFuturesParams : クエリパラメータ。Date は必須
+type GetFuturesParams struct {
+	Date                     string
+	Category                 string // 商品区分 (例: "NK225F")。空なら全商品
+	CentralContractMonthOnly bool   // true なら中心限月のみ
+}
+
+// GetOptionsParams : クエリパラメータ。Date は必須
+type GetOptionsParams struct {
+	Date                     string
+	Category                 string // 商品区分 (例: "NK225E", "EQOP")。空なら全商品
+	Code                     string // 有価証券オプションの原資産の銘柄コード
+	CentralContractMonthOnly bool
+}
+
+// GetIndexOptionParams : クエリパラメータ。Date は必須
+type GetIndexOptionParams struct {
+	Date string
+}
+
+// GetFutures は /derivatives/futures を全ページ取得し、[]FuturesQuote を返す
+func (c *JQuantsClient) GetFutures(params GetFuturesParams) ([]FuturesQuote, error) {
+	return c.GetFuturesWithContext(context.Background(), params)
+}
+
+// GetFuturesWithContext は GetFutures の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetFuturesWithContext(ctx context.Context, params GetFuturesParams) ([]FuturesQuote, error) {
+	return DoPaginatedGetWithContext[FuturesQuote](ctx, c, c.endpoint("/derivatives/futures"), params.values(), extractFutures)
+}
+
+// StreamFutures は /derivatives/futures を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamFutures(ctx context.Context, params GetFuturesParams, handle PageHandler[FuturesQuote]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/derivatives/futures"), params.values(), extractFutures, handle)
+}
+
+// GetOptions は /derivatives/options を全ページ取得し、[]OptionQuote を返す
+func (c *JQuantsClient) GetOptions(params GetOptionsParams) ([]OptionQuote, error) {
+	return c.GetOptionsWithContext(context.Background(), params)
+}
+
+// GetOptionsWithContext は GetOptions の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetOptionsWithContext(ctx context.Context, params GetOptionsParams) ([]OptionQuote, error) {
+	return DoPaginatedGetWithContext[OptionQuote](ctx, c, c.endpoint("/derivatives/options"), params.values(), extractOptions)
+}
+
+// StreamOptions は /derivatives/options を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamOptions(ctx context.Context, params GetOptionsParams, handle PageHandler[OptionQuote]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/derivatives/options"), params.values(), extractOptions, handle)
+}
+
+// GetIndexOption は /option/index_option (日経225オプション) を全ページ取得し、[]OptionQuote を返す
+func (c *JQuantsClient) GetIndexOption(params GetIndexOptionParams) ([]OptionQuote, error) {
+	return c.GetIndexOptionWithContext(context.Background(), params)
+}
+
+// GetIndexOptionWithContext は GetIndexOption の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetIndexOptionWithContext(ctx context.Context, params GetIndexOptionParams) ([]OptionQuote, error) {
+	return DoPaginatedGetWithContext[OptionQuote](ctx, c, c.endpoint("/option/index_option"), params.values(), extractIndexOption)
+}
+
+// StreamIndexOption は /option/index_option を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamIndexOption(ctx context.Context, params GetIndexOptionParams, handle PageHandler[OptionQuote]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/option/index_option"), params.values(), extractIndexOption, handle)
+}
+
+func (params GetFuturesParams) values() url.Values {
+	q := url.Values{}
+	if params.Date != "" {
+		q.Set("date", params.Date)
+	}
+	if params.Category != "" {
+		q.Set("category", params.Category)
+	}
+	if params.CentralContractMonthOnly {
+		q.Set("contract_flag", "1")
+	}
+	return q
+}
+
+func (params GetOptionsParams) values() url.Values {
+	q := url.Values{}
+	if params.Date != "" {
+		q.Set("date", params.Date)
+	}
+	if params.Category != "" {
+		q.Set("category", params.Category)
+	}
+	if params.Code != "" {
+		q.Set("code", params.Code)
+	}
+	if params.CentralContractMonthOnly {
+		q.Set("contract_flag", "1")
+	}
+	return q
+}
+
+func (params GetIndexOptionParams) values() url.Values {
+	q := url.Values{}
+	if params.Date != "" {
+		q.Set("date", params.Date)
+	}
+	return q
+}
+
+func extractFutures(respBytes []byte) ([]FuturesQuote, string, error) {
+	var r futuresResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal futures: %w", err)
+	}
+	return r.Futures, r.PaginationKey, nil
+}
+
+func extractOptions(respBytes []byte) ([]OptionQuote, string, error) {
+	var r optionsResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal options: %w", err)
+	}
+	return r.Options, r.PaginationKey, nil
+}
+
+func extractIndexOption(respBytes []byte) ([]OptionQuote, string, error) {
+	var r indexOptionResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal index_option: %w", err)
+	}
+	return r.IndexOption, r.PaginationKey, nil
+}
+
+// preferSettlement は同じ銘柄の既存の行 cur と q のうち、チェーンに使う方を返す
+func preferSettlement(cur, q *OptionQuote) *OptionQuote {
+	if cur != nil && cur.IsSettlement() && !q.IsSettlement() {
+		return cur
+	}
+	return q
+}
@@ -0,0 +1,80 @@
+package jquants
+
+import (
+	"net/http"
+	"testing"
+)
+
+const indexOptionJSON = `{"index_option":[
+	{"Date":"2024-01-04","Code":"130060018","ContractMonth":"2024-01","StrikePrice":33000,"PutCallDivision":"1","UnderlyingPrice":33288.29,"WholeDayClose":95,"NightSessionOpen":"","ImpliedVolatility":18.5,"OpenInterest":1200},
+	{"Date":"2024-01-04","Code":"140060018","ContractMonth":"2024-01","StrikePrice":33000,"PutCallDivision":"2","UnderlyingPrice":33288.29,"WholeDayClose":410,"NightSessionOpen":"","ImpliedVolatility":17.9,"OpenInterest":900},
+	{"Date":"2024-01-04","Code":"140060019","ContractMonth":"2024-01","StrikePrice":33500,"PutCallDivision":"2","UnderlyingPrice":33288.29,"WholeDayClose":120,"NightSessionOpen":"","ImpliedVolatility":16.2,"OpenInterest":700},
+	{"Date":"2024-01-04","Code":"140070019","ContractMonth":"2024-02","StrikePrice":33500,"PutCallDivision":"2","UnderlyingPrice":33288.29,"WholeDayClose":450,"NightSessionOpen":"","ImpliedVolatility":17.0,"OpenInterest":300}
+]}`
+
+func TestGetIndexOptionAndChains(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/option/index_option" || r.URL.Query().Get("date") != "2024-01-04" {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		w.Write([]byte(indexOptionJSON))
+	}))
+	quotes, err := c.GetIndexOption(GetIndexOptionParams{Date: "2024-01-04"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 4 || quotes[0].NightSessionOpen.Valid || quotes[0].PutCallDivision != Put {
+		t.Fatalf("Unexpected quotes: %+v", quotes)
+	}
+
+	chains := BuildOptionChains(quotes)
+	if len(chains) != 2 || chains[0].ContractMonth != "2024-01" || chains[1].ContractMonth != "2024-02" {
+		t.Fatalf("Unexpected chains: %+v", chains)
+	}
+	jan := chains[0]
+	if len(jan.Rows) != 2 || jan.Rows[0].StrikePrice != 33000 || jan.Rows[1].StrikePrice != 33500 {
+		t.Fatalf("Unexpected rows: %+v", jan.Rows)
+	}
+	if jan.Rows[0].Put == nil || jan.Rows[0].Call == nil || jan.Rows[1].Put != nil {
+		t.Errorf("Unexpected put/call pairing: %+v", jan.Rows)
+	}
+	if atm, ok := jan.AtTheMoney(); !ok || atm.StrikePrice != 33500 {
+		t.Errorf("Expected ATM strike 33500, got %v (ok=%v)", atm.StrikePrice, ok)
+	}
+}
+
+func TestBuildOptionChainsPrefersSettlementRows(t *testing.T) {
+	// 緊急取引証拠金の発動日は、同じ銘柄に 001 (発動時) と 002 (清算価格算出時) の行が返ってくる
+	option := func(division string, underlying, price float64) OptionQuote {
+		q := OptionQuote{PutCallDivision: Call, StrikePrice: Float(33000), UnderlyingPrice: Float(underlying)}
+		q.Date, q.Code, q.ContractMonth = "2024-01-04", "140060018", "2024-01"
+		q.EmergencyMarginTriggerDivision = division
+		q.WholeDayClose = Float(price)
+		return q
+	}
+	for _, quotes := range [][]OptionQuote{
+		{option("001", 33100, 300), option("002", 33288.29, 410)},
+		{option("002", 33288.29, 410), option("001", 33100, 300)},
+	} {
+		chains := BuildOptionChains(quotes)
+		if len(chains) != 1 || len(chains[0].Rows) != 1 {
+			t.Fatalf("Unexpected chains: %+v", chains)
+		}
+		ch := chains[0]
+		if call := ch.Rows[0].Call; call == nil || !call.IsSettlement() || call.WholeDayClose != Float(410) {
+			t.Errorf("Expected the settlement row, got %+v", call)
+		}
+		if ch.UnderlyingPrice != Float(33288.29) {
+			t.Errorf("Expected the settlement underlying price, got %v", ch.UnderlyingPrice)
+		}
+	}
+}
+
+func TestGetFuturesParams(t *testing.T) {
+	q := GetFuturesParams{Date: "2024-01-04", Category: "NK225F", CentralContractMonthOnly: true}.values()
+	if q.Get("date") != "2024-01-04" || q.Get("category") != "NK225F" || q.Get("contract_flag") != "1" {
+		t.Errorf("Unexpected query: %v", q)
+	}
+}
@@ -0,0 +1,115 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// CashDividend は /fins/dividend の配当通知1件分。1株あたりの金額は円
+type CashDividend struct {
+	AnnouncementDate          string      `json:"AnnouncementDate"`
+	AnnouncementTime          string      `json:"AnnouncementTime"`
+	Code                      string      `json:"Code"`
+	ReferenceNumber           string      `json:"ReferenceNumber"`
+	StatusCode                string      `json:"StatusCode"` // 1: 新規, 2: 訂正, 3: 削除
+	BoardMeetingDate          string      `json:"BoardMeetingDate"`
+	InterimFinalCode          string      `json:"InterimFinalCode"`   // 1: 中間配当, 2: 期末配当
+	ForecastResultCode        string      `json:"ForecastResultCode"` // 1: 決定, 2: 予想
+	InterimFinalTerm          string      `json:"InterimFinalTerm"`   // 配当基準日の年月 (例: "2024-03")
+	GrossDividendRate         NullFloat64 `json:"GrossDividendRate"`
+	RecordDate                string      `json:"RecordDate"`
+	ExDate                    string      `json:"ExDate"`
+	ActualRecordDate          string      `json:"ActualRecordDate"`
+	PayableDate               string      `json:"PayableDate"` // 未定のときは "-"
+	CAReferenceNumber         string      `json:"CAReferenceNumber"`
+	DistributionAmount        NullFloat64 `json:"DistributionAmount"`
+	RetainedEarnings          NullFloat64 `json:"RetainedEarnings"`
+	DeemedDividend            NullFloat64 `json:"DeemedDividend"`
+	DeemedCapitalGains        NullFloat64 `json:"DeemedCapitalGains"`
+	NetAssetDecreaseRatio     NullFloat64 `json:"NetAssetDecreaseRatio"`
+	CommemorativeSpecialCode  string      `json:"CommemorativeSpecialCode"` // 0: 通常, 1: 記念, 2: 特別, 3: 記念・特別
+	CommemorativeDividendRate NullFloat64 `json:"CommemorativeDividendRate"`
+	SpecialDividendRate       NullFloat64 `json:"SpecialDividendRate"`
+}
+
+// IsDeleted は取り消された通知かどうかを返す
+func (d CashDividend) IsDeleted() bool {
+	return d.StatusCode == "3"
+}
+
+// IsForecast は予想 (未決定) の配当かどうかを返す
+func (d CashDividend) IsForecast() bool {
+	return d.ForecastResultCode == "2"
+}
+
+// LatestDividends は同じ配当 (CAReferenceNumber) に対する訂正を畳み込み、最新の通知だけを返す。
+// 削除された配当は結果に含めない
+func LatestDividends(divs []CashDividend) []CashDividend {
+	latest := make(map[string]CashDividend)
+	var order []string
+	for _, d := range divs {
+		key := d.CAReferenceNumber
+		if key == "" {
+			key = d.ReferenceNumber
+		}
+		prev, ok := latest[key]
+		if !ok {
+			order = append(order, key)
+		}
+		if !ok || d.AnnouncementDate+d.AnnouncementTime >= prev.AnnouncementDate+prev.AnnouncementTime {
+			latest[key] = d
+		}
+	}
+
+	var result []CashDividend
+	for _, key := range order {
+		if d := latest[key]; !d.IsDeleted() {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// cashDividendResponse : JSON全体を受け取るための構造
+type cashDividendResponse struct {
+	Dividend      []CashDividend `json:"dividend"`
+	PaginationKey string         `json:"pagination_key"`
+}
+
+// GetDividendParams : クエリパラメータ。Code か Date のどちらかは必須。Date / From / To は通知日
+type GetDividendParams struct {
+	Code string
+	Date string
+	From string
+	To   string
+}
+
+// GetDividend は /fins/dividend を全ページ取得し、[]CashDividend を返す
+func (c *JQuantsClient) GetDividend(params GetDividendParams) ([]CashDividend, error) {
+	return c.GetDividendWithContext(context.Background(), params)
+}
+
+// GetDividendWithContext は GetDividend の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetDividendWithContext(ctx context.Context, params GetDividendParams) ([]CashDividend, error) {
+	return DoPaginatedGetWithContext[CashDividend](ctx, c, c.endpoint("/fins/dividend"), params.values(), extractDividend)
+}
+
+// StreamDividend は /fins/dividend を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamDividend(ctx context.Context, params GetDividendParams, handle PageHandler[CashDividend]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/fins/dividend"), params.values(), extractDividend, handle)
+}
+
+func (params GetDividendParams) values() url.Values {
+	return codeDateRangeValues(params.Code, params.Date, params.From, params.To)
+}
+
+func extractDividend(respBytes []byte) ([]CashDividend, string, error) {
+	var r cashDividendResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal dividend: %w", err)
+	}
+	return r.Dividend, r.PaginationKey, nil
+}
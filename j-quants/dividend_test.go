@@ -0,0 +1,61 @@
+package jquants
+
+import (
+	"net/http"
+	"testing"
+)
+
+const dividendJSON = `{"dividend":[
+	{"AnnouncementDate":"2024-02-06","AnnouncementTime":"15:00","Code":"72030","ReferenceNumber":"1","StatusCode":"1","InterimFinalCode":"2","ForecastResultCode":"2","GrossDividendRate":"-","RecordDate":"2024-03-31","ExDate":"2024-03-28","PayableDate":"-","CAReferenceNumber":"100","CommemorativeSpecialCode":"0"},
+	{"AnnouncementDate":"2024-05-08","AnnouncementTime":"15:00","Code":"72030","ReferenceNumber":"2","StatusCode":"2","InterimFinalCode":"2","ForecastResultCode":"1","GrossDividendRate":45,"RecordDate":"2024-03-31","ExDate":"2024-03-28","PayableDate":"2024-05-28","CAReferenceNumber":"100","CommemorativeSpecialCode":"0"},
+	{"AnnouncementDate":"2024-02-06","AnnouncementTime":"15:00","Code":"91040","ReferenceNumber":"3","StatusCode":"1","GrossDividendRate":"120","CAReferenceNumber":"200"},
+	{"AnnouncementDate":"2024-02-07","AnnouncementTime":"09:00","Code":"91040","ReferenceNumber":"4","StatusCode":"3","GrossDividendRate":"","CAReferenceNumber":"200"}
+]}`
+
+func TestGetDividend(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/fins/dividend" || q.Get("code") != "7203" || q.Get("from") != "2024-02-01" || q.Get("to") != "2024-05-31" || q.Has("date") {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		switch q.Get("pagination_key") {
+		case "":
+			w.Write([]byte(`{"dividend":[{"AnnouncementDate":"2024-02-06","Code":"72030","ReferenceNumber":"1","StatusCode":"1","ForecastResultCode":"2","GrossDividendRate":"-","ExDate":"2024-03-28","CAReferenceNumber":"100"}],"pagination_key":"next"}`))
+		case "next":
+			w.Write([]byte(`{"dividend":[{"AnnouncementDate":"2024-05-08","Code":"72030","ReferenceNumber":"2","StatusCode":"2","ForecastResultCode":"1","GrossDividendRate":45,"ExDate":"2024-03-28","CAReferenceNumber":"100"}]}`))
+		default:
+			t.Errorf("Unexpected pagination key: %s", q.Get("pagination_key"))
+		}
+	}))
+
+	divs, err := c.GetDividend(GetDividendParams{Code: "7203", From: "2024-02-01", To: "2024-05-31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(divs) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(divs))
+	}
+	if divs[0].GrossDividendRate.Valid || divs[1].GrossDividendRate != Float(45) || divs[1].ReferenceNumber != "2" {
+		t.Errorf("Unexpected dividends: %+v", divs)
+	}
+}
+
+func TestLatestDividends(t *testing.T) {
+	divs, _, err := extractDividend([]byte(dividendJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if divs[0].GrossDividendRate.Valid || !divs[1].GrossDividendRate.Valid || divs[2].GrossDividendRate.Float64 != 120 {
+		t.Errorf("Unexpected dividend rates: %v %v %v", divs[0].GrossDividendRate, divs[1].GrossDividendRate, divs[2].GrossDividendRate)
+	}
+
+	latest := LatestDividends(divs)
+	if len(latest) != 1 {
+		t.Fatalf("Expected the deleted dividend to be dropped, got %+v", latest)
+	}
+	if latest[0].ReferenceNumber != "2" || latest[0].IsForecast() || latest[0].GrossDividendRate.Float64 != 45 {
+		t.Errorf("Expected the revised dividend, got %+v", latest[0])
+	}
+}
@@ -0,0 +1,142 @@
+package jquants
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// errors.Is で判定するための J-Quants API エラーの分類
+var (
+	ErrBadRequest     = errors.New("jquants: bad request")
+	ErrUnauthorized   = errors.New("jquants: unauthorized")
+	ErrPlanRestricted = errors.New("jquants: not available on current subscription plan")
+	ErrNotFound       = errors.New("jquants: not found")
+	ErrRateLimited    = errors.New("jquants: rate limited")
+	ErrServer         = errors.New("jquants: server error")
+)
+
+// APIError は J-Quants API が 200 以外を返したときのエラー
+type APIError struct {
+	StatusCode int
+	Message    string        // レスポンスの "message"。JSON でなければ本文そのまま
+	Endpoint   string        // 例: "/prices/daily_quotes"
+	Params     url.Values    // リクエストのクエリパラメータ (トークンは伏せてある)
+	RetryAfter time.Duration // Retry-After ヘッダの値。指定がなければ 0
+}
+
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "jquants: %s failed: status=%d", e.Endpoint, e.StatusCode)
+	if len(e.Params) > 0 {
+		fmt.Fprintf(&sb, ", params=%s", e.Params.Encode())
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&sb, ", message=%s", e.Message)
+	}
+	return sb.String()
+}
+
+// Is は errors.Is(err, ErrRateLimited) のようにステータスの分類で判定できるようにする
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrPlanRestricted:
+		return e.isPlanRestricted()
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// isPlanRestricted は契約プランの範囲外へのアクセスかどうかを返す。
+// J-Quants はプラン外の API には 403 を、プラン外の期間指定には 400 と "subscription" を含むメッセージを返す
+func (e *APIError) isPlanRestricted() bool {
+	if e.StatusCode == http.StatusForbidden {
+		return true
+	}
+	return e.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(e.Message), "subscription")
+}
+
+// newAPIError はレスポンスから APIError を作る
+func newAPIError(endpoint string, query url.Values, resp *http.Response, body []byte, now time.Time) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Endpoint:   endpoint,
+		Params:     redactParams(query),
+		Message:    strings.TrimSpace(string(body)),
+	}
+	var r struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &r); err == nil && r.Message != "" {
+		e.Message = r.Message
+	}
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+		e.RetryAfter = d
+	}
+	return e
+}
+
+// redactParams はエラーに残すとまずいトークン類をクエリから伏せる
+func redactParams(q url.Values) url.Values {
+	if len(q) == 0 {
+		return nil
+	}
+	redacted := url.Values{}
+	for k, v := range q {
+		if k == "refreshtoken" {
+			redacted.Set(k, "REDACTED")
+			continue
+		}
+		redacted[k] = append([]string(nil), v...)
+	}
+	return redacted
+}
+
+// redactURL はエラーやキャッシュに残す URL からトークンを除く
+func redactURL(fullURL string) string {
+	u, err := url.Parse(fullURL)
+	if err != nil {
+		return ""
+	}
+	u.RawQuery = redactParams(u.Query()).Encode()
+	return u.String()
+}
+
+// redactURLError は通信エラーに含まれる URL からトークンを除く。
+// *url.Error の文字列にはリクエストの URL がそのまま入るため、auth_refresh のリフレッシュトークンが漏れないようにする
+func redactURLError(err error) error {
+	urlErr, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	redacted := *urlErr
+	redacted.URL = redactURL(urlErr.URL)
+	return &redacted
+}
+
+// hasStatus は err が指定のいずれかのステータスのレスポンスによるものかを返す
+func hasStatus(err error, codes ...int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range codes {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package jquants
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAPIErrorClassification(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantIs  []error
+		wantNot []error
+	}{
+		{"plan restricted endpoint", http.StatusForbidden, `{"message":"This API is not available on your subscription."}`, []error{ErrPlanRestricted}, []error{ErrBadRequest}},
+		{"plan restricted dates", http.StatusBadRequest, `{"message":"Your subscription covers the following dates: 2022-01-01 ~ "}`, []error{ErrPlanRestricted, ErrBadRequest}, []error{ErrUnauthorized}},
+		{"bad parameter", http.StatusBadRequest, `{"message":"'code' is invalid."}`, []error{ErrBadRequest}, []error{ErrPlanRestricted}},
+		{"rate limited", http.StatusTooManyRequests, `{"message":"Too Many Requests"}`, []error{ErrRateLimited}, []error{ErrServer}},
+		{"server error", http.StatusInternalServerError, `Internal Server Error`, []error{ErrServer}, []error{ErrRateLimited}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}), WithRetryPolicy(NoRetry))
+
+			_, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"})
+			for _, target := range tt.wantIs {
+				if !errors.Is(err, target) {
+					t.Errorf("Expected errors.Is(%v, %v)", err, target)
+				}
+			}
+			for _, target := range tt.wantNot {
+				if errors.Is(err, target) {
+					t.Errorf("Expected !errors.Is(%v, %v)", err, target)
+				}
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected *APIError, got: %T", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Endpoint != "/prices/daily_quotes" || apiErr.Params.Get("code") != "7203" {
+				t.Errorf("Unexpected APIError: %+v", apiErr)
+			}
+			if strings.Contains(tt.body, "message") && strings.Contains(apiErr.Message, "{") {
+				t.Errorf("Expected message to be extracted from JSON, got: %s", apiErr.Message)
+			}
+		})
+	}
+}
+
+func TestAPIErrorRedactsRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"'refreshtoken' is invalid."}`))
+	}))
+
+	_, _, err := c.getIDTokenByRefreshToken(context.Background(), "secret_rt")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "secret_rt") || apiErr.Params.Get("refreshtoken") != "REDACTED" {
+		t.Errorf("Expected refresh token to be redacted, got: %v", err)
+	}
+	if apiErr.Endpoint != "/token/auth_refresh" {
+		t.Errorf("Unexpected endpoint: %s", apiErr.Endpoint)
+	}
+}
+
+func TestTransportErrorRedactsRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	// 接続できないサーバーでは *url.Error に URL がそのまま入る
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	c := newClient(WithBaseURL(srv.URL), WithTokenStore(NewMemoryTokenStore(nil)), WithRetryPolicy(RetryPolicy{MaxAttempts: 2}))
+	recordSleeps(c)
+
+	_, _, err := c.getIDTokenByRefreshToken(context.Background(), "secret_rt")
+	if err == nil {
+		t.Fatal("Expected error from a dead server")
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Fatalf("Expected *url.Error, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "secret_rt") || !strings.Contains(urlErr.URL, "refreshtoken=REDACTED") {
+		t.Errorf("Expected refresh token to be redacted, got: %v", err)
+	}
+}
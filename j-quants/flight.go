@@ -0,0 +1,52 @@
+package jquants
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// flightGroup は同時に呼ばれた同じ処理を1回の実行にまとめる (singleflight)。
+// 実行中に呼ばれた Do は新たに fn を実行せず、先行する実行の結果を受け取る
+type flightGroup struct {
+	mu   sync.Mutex
+	call *flightCall
+}
+
+type flightCall struct {
+	done chan struct{}
+	err  error
+}
+
+// Do は fn を実行してその結果を返す。既に実行中であればその完了を待つ。
+// fn は最初の呼び出し元の ctx から切り離した context (キャンセルされず、timeout で打ち切る) で実行する。
+// 最初の呼び出し元がキャンセルしても、一緒に待っている他の呼び出し元の実行は失敗させないため。
+// どの呼び出し元も、自分の ctx がキャンセルされたら実行の完了を待たずに戻る
+func (g *flightGroup) Do(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	g.mu.Lock()
+	call := g.call
+	if call == nil {
+		call = &flightCall{done: make(chan struct{})}
+		g.call = call
+		go g.run(ctx, timeout, call, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (g *flightGroup) run(ctx context.Context, timeout time.Duration, call *flightCall, fn func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	call.err = fn(ctx)
+
+	g.mu.Lock()
+	g.call = nil
+	g.mu.Unlock()
+	close(call.done)
+}
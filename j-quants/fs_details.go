@@ -0,0 +1,161 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// AccountingStandard は財務諸表の会計基準
+type AccountingStandard string
+
+const (
+	AccountingStandardJGAAP  AccountingStandard = "JP"
+	AccountingStandardIFRS   AccountingStandard = "IFRS"
+	AccountingStandardUSGAAP AccountingStandard = "US"
+)
+
+// FSDetail は /fins/fs_details の1開示分の財務諸表 (BS/PL/CF の各科目)
+type FSDetail struct {
+	DisclosedDate      string                  `json:"DisclosedDate"`
+	DisclosedTime      string                  `json:"DisclosedTime"`
+	LocalCode          string                  `json:"LocalCode"`
+	DisclosureNumber   string                  `json:"DisclosureNumber"`
+	TypeOfDocument     string                  `json:"TypeOfDocument"` // 例: "FYFinancialStatements_Consolidated_IFRS"
+	FinancialStatement FinancialStatementItems `json:"FinancialStatement"`
+}
+
+// IsConsolidated は連結の財務諸表かどうかを返す
+func (d FSDetail) IsConsolidated() bool {
+	return strings.Contains(d.TypeOfDocument, "_Consolidated")
+}
+
+// AccountingStandard は TypeOfDocument の末尾から会計基準を返す。読み取れなければ空文字
+func (d FSDetail) AccountingStandard() AccountingStandard {
+	return accountingStandardOf(d.TypeOfDocument)
+}
+
+// accountingStandardOf は "FYFinancialStatements_Consolidated_IFRS" のような書類種別の末尾から会計基準を読む
+func accountingStandardOf(typeOfDocument string) AccountingStandard {
+	i := strings.LastIndex(typeOfDocument, "_")
+	if i < 0 {
+		return ""
+	}
+	switch std := AccountingStandard(typeOfDocument[i+1:]); std {
+	case AccountingStandardJGAAP, AccountingStandardIFRS, AccountingStandardUSGAAP:
+		return std
+	}
+	return ""
+}
+
+// FinancialStatementItems は XBRL のタクソノミ要素名 (例: "Goodwill (IFRS)", "NetSales") をキーにした科目の値。
+// 値は J-Quants が返す文字列のまま保持し、数値として読む場合は Float を使う
+type FinancialStatementItems map[string]string
+
+// Lookup は要素名に対応する値を返す。完全一致が無ければ "(IFRS)" のような会計基準の付記を除いた名前で探す。
+// 付記を除くと同じ名前になる要素が複数あれば、呼ぶたびに結果が変わらないよう要素名の昇順で最初のものを返す
+func (items FinancialStatementItems) Lookup(element string) (string, bool) {
+	if v, ok := items[element]; ok {
+		return v, true
+	}
+	want := normalizeElement(element)
+	for _, k := range items.Elements() {
+		if normalizeElement(k) == want {
+			return items[k], true
+		}
+	}
+	return "", false
+}
+
+// Float は要素の値を数値として返す。要素が無い、または値が空の場合は Valid = false
+func (items FinancialStatementItems) Float(element string) (NullFloat64, error) {
+	v, ok := items.Lookup(element)
+	if !ok {
+		return NullFloat64{}, nil
+	}
+	n, err := parseNullFloat64(v)
+	if err != nil {
+		return NullFloat64{}, fmt.Errorf("%s: %w", element, err)
+	}
+	return n, nil
+}
+
+// Elements は含まれる要素名を昇順で返す
+func (items FinancialStatementItems) Elements() []string {
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalizeElement は要素名から会計基準の付記を除き、大文字小文字を揃える
+func normalizeElement(name string) string {
+	name = strings.TrimSpace(name)
+	for _, suffix := range []string{"(IFRS)", "(US GAAP)", "(JGAAP)"} {
+		name = strings.TrimSpace(strings.TrimSuffix(name, suffix))
+	}
+	return strings.ToLower(name)
+}
+
+// FilterFSDetails は連結 (consolidated = true) または単体の財務諸表だけを返す
+func FilterFSDetails(details []FSDetail, consolidated bool) []FSDetail {
+	var result []FSDetail
+	for _, d := range details {
+		if d.IsConsolidated() == consolidated {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// fsDetailsResponse : JSON全体を受け取るための構造
+type fsDetailsResponse struct {
+	FSDetails     []FSDetail `json:"fs_details"`
+	PaginationKey string     `json:"pagination_key"`
+}
+
+// GetFSDetailsParams : クエリパラメータ。Code か Date のどちらかは必須
+type GetFSDetailsParams struct {
+	Code string
+	Date string
+}
+
+// GetFSDetails は /fins/fs_details を全ページ取得し、[]FSDetail を返す
+func (c *JQuantsClient) GetFSDetails(params GetFSDetailsParams) ([]FSDetail, error) {
+	return c.GetFSDetailsWithContext(context.Background(), params)
+}
+
+// GetFSDetailsWithContext は GetFSDetails の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetFSDetailsWithContext(ctx context.Context, params GetFSDetailsParams) ([]FSDetail, error) {
+	return DoPaginatedGetWithContext[FSDetail](ctx, c, c.endpoint("/fins/fs_details"), params.values(), extractFSDetails)
+}
+
+// StreamFSDetails は /fins/fs_details を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamFSDetails(ctx context.Context, params GetFSDetailsParams, handle PageHandler[FSDetail]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/fins/fs_details"), params.values(), extractFSDetails, handle)
+}
+
+func (params GetFSDetailsParams) values() url.Values {
+	q := url.Values{}
+	if params.Code != "" {
+		q.Set("code", params.Code)
+	}
+	if params.Date != "" {
+		q.Set("date", params.Date)
+	}
+	return q
+}
+
+func extractFSDetails(respBytes []byte) ([]FSDetail, string, error) {
+	var r fsDetailsResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal fs_details: %w", err)
+	}
+	return r.FSDetails, r.PaginationKey, nil
+}
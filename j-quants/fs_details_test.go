@@ -0,0 +1,62 @@
+package jquants
+
+import (
+	"testing"
+)
+
+const fsDetailsJSON = `{"fs_details":[
+	{"DisclosedDate":"2024-05-08","DisclosedTime":"13:55:00","LocalCode":"72030","DisclosureNumber":"20240508500000","TypeOfDocument":"FYFinancialStatements_Consolidated_IFRS",
+	 "FinancialStatement":{"EDINET code":"E02144","Goodwill (IFRS)":"1500000000","Revenue - 2 (IFRS)":"45095325000000","Total assets (IFRS)":"90114296000000","Dividends paid (IFRS)":""}},
+	{"DisclosedDate":"2024-05-08","DisclosedTime":"13:55:00","LocalCode":"72030","DisclosureNumber":"20240508500001","TypeOfDocument":"FYFinancialStatements_NonConsolidated_JP",
+	 "FinancialStatement":{"NetSales":"17000000000000"}}
+]}`
+
+func TestFSDetails(t *testing.T) {
+	details, _, err := extractFSDetails([]byte(fsDetailsJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	consolidated := FilterFSDetails(details, true)
+	if len(consolidated) != 1 || consolidated[0].AccountingStandard() != AccountingStandardIFRS {
+		t.Fatalf("Unexpected consolidated statements: %+v", consolidated)
+	}
+	if nonCons := FilterFSDetails(details, false); len(nonCons) != 1 || nonCons[0].AccountingStandard() != AccountingStandardJGAAP {
+		t.Errorf("Unexpected non-consolidated statements: %+v", nonCons)
+	}
+
+	items := consolidated[0].FinancialStatement
+	if v, ok := items.Lookup("EDINET code"); !ok || v != "E02144" {
+		t.Errorf("Unexpected EDINET code: %s", v)
+	}
+	// 会計基準の付記を省略しても引ける
+	assets, err := items.Float("Total assets")
+	if err != nil || !assets.Valid || assets.Float64 != 90114296000000 {
+		t.Errorf("Unexpected total assets: %v (err=%v)", assets, err)
+	}
+	if v, err := items.Float("Dividends paid (IFRS)"); err != nil || v.Valid {
+		t.Errorf("Expected empty value to be invalid, got %v (err=%v)", v, err)
+	}
+	if _, err := items.Float("EDINET code"); err == nil {
+		t.Error("Expected error for a non-numeric element")
+	}
+	if elems := items.Elements(); len(elems) != 5 || elems[0] != "Dividends paid (IFRS)" {
+		t.Errorf("Unexpected elements: %v", elems)
+	}
+}
+
+func TestFinancialStatementItemsLookupIsDeterministic(t *testing.T) {
+	items := FinancialStatementItems{
+		"goodwill":         "3",
+		"Goodwill (JGAAP)": "1",
+		"Goodwill (IFRS)":  "2",
+	}
+	// 付記を除くとすべて同じ名前になるが、map の順序によらず同じ値を返す
+	for i := 0; i < 100; i++ {
+		if v, ok := items.Lookup("Goodwill"); !ok || v != "2" {
+			t.Fatalf("Expected the first element in sorted order, got %q (ok=%v)", v, ok)
+		}
+	}
+	if v, ok := items.Lookup("Goodwill (JGAAP)"); !ok || v != "1" {
+		t.Errorf("Expected an exact match to take precedence, got %q", v)
+	}
+}
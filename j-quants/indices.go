@@ -0,0 +1,185 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// IndexCode は指数コード
+type IndexCode string
+
+const (
+	IndexTOPIX          IndexCode = "0000"
+	IndexTOPIXCore30    IndexCode = "0028"
+	IndexTOPIXLarge70   IndexCode = "0029"
+	IndexTOPIX100       IndexCode = "002A"
+	IndexTOPIXMid400    IndexCode = "002B"
+	IndexTOPIX500       IndexCode = "002C"
+	IndexTOPIXSmall     IndexCode = "002D"
+	IndexTOPIX1000      IndexCode = "002E"
+	IndexTOPIXSmall500  IndexCode = "002F"
+	IndexPrimeMarket    IndexCode = "0500" // 東証プライム市場指数
+	IndexStandardMarket IndexCode = "0501" // 東証スタンダード市場指数
+	IndexGrowthMarket   IndexCode = "0502" // 東証グロース市場指数
+)
+
+// sector33IndexCodes は33業種コードと東証業種別株価指数のコードの対応
+var sector33IndexCodes = map[Sector33Code]IndexCode{
+	Sector33Fishery:                  "0040",
+	Sector33Mining:                   "0041",
+	Sector33Construction:             "0042",
+	Sector33Foods:                    "0043",
+	Sector33Textiles:                 "0044",
+	Sector33PulpPaper:                "0045",
+	Sector33Chemicals:                "0046",
+	Sector33Pharmaceutical:           "0047",
+	Sector33OilCoal:                  "0048",
+	Sector33Rubber:                   "0049",
+	Sector33GlassCeramics:            "004A",
+	Sector33IronSteel:                "004B",
+	Sector33NonferrousMetals:         "004C",
+	Sector33MetalProducts:            "004D",
+	Sector33Machinery:                "004E",
+	Sector33ElectricAppliances:       "004F",
+	Sector33TransportEquipment:       "0050",
+	Sector33PrecisionInstruments:     "0051",
+	Sector33OtherProducts:            "0052",
+	Sector33ElectricPowerGas:         "0053",
+	Sector33LandTransportation:       "0054",
+	Sector33MarineTransportation:     "0055",
+	Sector33AirTransportation:        "0056",
+	Sector33WarehousingHarbor:        "0057",
+	Sector33InformationCommunication: "0058",
+	Sector33Wholesale:                "0059",
+	Sector33Retail:                   "005A",
+	Sector33Banks:                    "005B",
+	Sector33Securities:               "005C",
+	Sector33Insurance:                "005D",
+	Sector33OtherFinancing:           "005E",
+	Sector33RealEstate:               "005F",
+	Sector33Services:                 "0060",
+}
+
+// SectorIndexCode は33業種に対応する東証業種別株価指数のコードを返す。対応する指数が無ければ false
+func SectorIndexCode(sector Sector33Code) (IndexCode, bool) {
+	code, ok := sector33IndexCodes[sector]
+	return code, ok
+}
+
+// IndexBar は指数の1日分の四本値
+type IndexBar struct {
+	Date  string    `json:"Date"`
+	Code  IndexCode `json:"Code"`
+	Open  float64   `json:"Open"`
+	High  float64   `json:"High"`
+	Low   float64   `json:"Low"`
+	Close float64   `json:"Close"`
+}
+
+// indicesResponse : /indices の JSON全体を受け取るための構造
+type indicesResponse struct {
+	Indices       []IndexBar `json:"indices"`
+	PaginationKey string     `json:"pagination_key"`
+}
+
+// topixResponse : /indices/topix の JSON全体を受け取るための構造
+type topixResponse struct {
+	TOPIX         []IndexBar `json:"topix"`
+	PaginationKey string     `json:"pagination_key"`
+}
+
+// GetIndicesParams : /indices のクエリパラメータ。Code か Date のどちらかは必須
+type GetIndicesParams struct {
+	Code IndexCode
+	Date string
+	From string
+	To   string
+}
+
+// GetTOPIXParams : /indices/topix のクエリパラメータ
+type GetTOPIXParams struct {
+	From string
+	To   string
+}
+
+// GetIndices は /indices を全ページ取得し、[]IndexBar を返す
+func (c *JQuantsClient) GetIndices(params GetIndicesParams) ([]IndexBar, error) {
+	return c.GetIndicesWithContext(context.Background(), params)
+}
+
+// GetIndicesWithContext は GetIndices の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetIndicesWithContext(ctx context.Context, params GetIndicesParams) ([]IndexBar, error) {
+	return DoPaginatedGetWithContext[IndexBar](ctx, c, c.endpoint("/indices"), params.values(), extractIndices)
+}
+
+// StreamIndices は /indices を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamIndices(ctx context.Context, params GetIndicesParams, handle PageHandler[IndexBar]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/indices"), params.values(), extractIndices, handle)
+}
+
+// GetTOPIX は /indices/topix を全ページ取得し、[]IndexBar を返す。Code には IndexTOPIX が入る
+func (c *JQuantsClient) GetTOPIX(params GetTOPIXParams) ([]IndexBar, error) {
+	return c.GetTOPIXWithContext(context.Background(), params)
+}
+
+// GetTOPIXWithContext は GetTOPIX の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetTOPIXWithContext(ctx context.Context, params GetTOPIXParams) ([]IndexBar, error) {
+	return DoPaginatedGetWithContext[IndexBar](ctx, c, c.endpoint("/indices/topix"), params.values(), extractTOPIX)
+}
+
+// StreamTOPIX は /indices/topix を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamTOPIX(ctx context.Context, params GetTOPIXParams, handle PageHandler[IndexBar]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/indices/topix"), params.values(), extractTOPIX, handle)
+}
+
+func (params GetIndicesParams) values() url.Values {
+	q := url.Values{}
+	if params.Code != "" {
+		q.Set("code", string(params.Code))
+	}
+	if params.Date != "" {
+		q.Set("date", params.Date)
+	}
+	if params.From != "" {
+		q.Set("from", params.From)
+	}
+	if params.To != "" {
+		q.Set("to", params.To)
+	}
+	return q
+}
+
+func (params GetTOPIXParams) values() url.Values {
+	q := url.Values{}
+	if params.From != "" {
+		q.Set("from", params.From)
+	}
+	if params.To != "" {
+		q.Set("to", params.To)
+	}
+	return q
+}
+
+func extractIndices(respBytes []byte) ([]IndexBar, string, error) {
+	var r indicesResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal indices: %w", err)
+	}
+	return r.Indices, r.PaginationKey, nil
+}
+
+func extractTOPIX(respBytes []byte) ([]IndexBar, string, error) {
+	var r topixResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal topix: %w", err)
+	}
+	// /indices/topix のレスポンスには Code が無いので補っておく
+	for i := range r.TOPIX {
+		r.TOPIX[i].Code = IndexTOPIX
+	}
+	return r.TOPIX, r.PaginationKey, nil
+}
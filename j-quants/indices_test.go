@@ -0,0 +1,110 @@
+package jquants
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetIndices(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/indices" || q.Get("code") != "0028" || q.Get("from") != "2024-01-04" || q.Get("to") != "2024-01-05" {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		switch q.Get("pagination_key") {
+		case "":
+			w.Write([]byte(`{"indices":[{"Date":"2024-01-04","Code":"0028","Open":1177.51,"High":1180.43,"Low":1165.23,"Close":1170.94}],"pagination_key":"next"}`))
+		case "next":
+			w.Write([]byte(`{"indices":[{"Date":"2024-01-05","Code":"0028","Open":1172.33,"High":1185.46,"Low":1171.72,"Close":1183.58}]}`))
+		default:
+			t.Errorf("Unexpected pagination key: %s", q.Get("pagination_key"))
+		}
+	}))
+
+	bars, err := c.GetIndices(GetIndicesParams{Code: IndexTOPIXCore30, From: "2024-01-04", To: "2024-01-05"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bars) != 2 || bars[1].Date != "2024-01-05" || bars[1].Code != IndexTOPIXCore30 || bars[1].Close != 1183.58 {
+		t.Errorf("Unexpected indices: %+v", bars)
+	}
+}
+
+func TestGetTOPIX(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/indices/topix" || q.Get("from") != "2024-01-04" || q.Has("code") {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		switch q.Get("pagination_key") {
+		case "":
+			w.Write([]byte(`{"topix":[{"Date":"2024-01-04","Open":2373.62,"High":2375.45,"Low":2347.82,"Close":2360.27}],"pagination_key":"next"}`))
+		case "next":
+			w.Write([]byte(`{"topix":[{"Date":"2024-01-05","Open":2364.65,"High":2384.76,"Low":2362.42,"Close":2378.48}]}`))
+		default:
+			t.Errorf("Unexpected pagination key: %s", q.Get("pagination_key"))
+		}
+	}))
+
+	bars, err := c.GetTOPIX(GetTOPIXParams{From: "2024-01-04"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bars) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(bars))
+	}
+	// レスポンスに無い Code はすべてのページで補われる
+	for _, b := range bars {
+		if b.Code != IndexTOPIX {
+			t.Errorf("Expected code %s, got %+v", IndexTOPIX, b)
+		}
+	}
+	if bars[0].Open != 2373.62 || bars[1].Close != 2378.48 {
+		t.Errorf("Unexpected topix: %+v", bars)
+	}
+}
+
+func TestSectorIndexCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sector Sector33Code
+		code   IndexCode
+		ok     bool
+	}{
+		{Sector33Fishery, "0040", true},
+		{Sector33TransportEquipment, "0050", true},
+		{Sector33InformationCommunication, "0058", true},
+		{Sector33Services, "0060", true},
+		{Sector33Other, "", false},
+		{"1234", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		code, ok := SectorIndexCode(tt.sector)
+		if code != tt.code || ok != tt.ok {
+			t.Errorf("SectorIndexCode(%q) = %q, %v; want %q, %v", tt.sector, code, ok, tt.code, tt.ok)
+		}
+	}
+
+	// その他以外の33業種すべてに別々の指数がある
+	seen := make(map[IndexCode]Sector33Code)
+	for sector := range sector33Names {
+		if sector == Sector33Other {
+			continue
+		}
+		code, ok := SectorIndexCode(sector)
+		if !ok {
+			t.Errorf("Expected an index for %s (%s)", sector, sector.Name())
+			continue
+		}
+		if other, dup := seen[code]; dup {
+			t.Errorf("Index %s is shared by %s and %s", code, other, sector)
+		}
+		seen[code] = sector
+	}
+}
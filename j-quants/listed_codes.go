@@ -0,0 +1,185 @@
+package jquants
+
+// /listed/info で使われるコード値の定義。
+// 名称は J-Quants API のドキュメントの「業種コード」「市場区分コード」等の一覧に合わせている
+
+// Sector17Code は17業種コード
+type Sector17Code string
+
+const (
+	Sector17Foods                     Sector17Code = "1"
+	Sector17EnergyResources           Sector17Code = "2"
+	Sector17ConstructionMaterials     Sector17Code = "3"
+	Sector17RawMaterialsChemicals     Sector17Code = "4"
+	Sector17Pharmaceutical            Sector17Code = "5"
+	Sector17AutomobilesTransportation Sector17Code = "6"
+	Sector17SteelNonferrousMetals     Sector17Code = "7"
+	Sector17Machinery                 Sector17Code = "8"
+	Sector17ElectricPrecision         Sector17Code = "9"
+	Sector17ITServicesOthers          Sector17Code = "10"
+	Sector17ElectricPowerGas          Sector17Code = "11"
+	Sector17TransportationLogistics   Sector17Code = "12"
+	Sector17CommercialWholesale       Sector17Code = "13"
+	Sector17Retail                    Sector17Code = "14"
+	Sector17Banks                     Sector17Code = "15"
+	Sector17FinancialsExBanks         Sector17Code = "16"
+	Sector17RealEstate                Sector17Code = "17"
+	Sector17Other                     Sector17Code = "99"
+)
+
+var sector17Names = map[Sector17Code]string{
+	Sector17Foods:                     "食品",
+	Sector17EnergyResources:           "エネルギー資源",
+	Sector17ConstructionMaterials:     "建設・資材",
+	Sector17RawMaterialsChemicals:     "素材・化学",
+	Sector17Pharmaceutical:            "医薬品",
+	Sector17AutomobilesTransportation: "自動車・輸送機",
+	Sector17SteelNonferrousMetals:     "鉄鋼・非鉄",
+	Sector17Machinery:                 "機械",
+	Sector17ElectricPrecision:         "電機・精密",
+	Sector17ITServicesOthers:          "情報通信・サービスその他",
+	Sector17ElectricPowerGas:          "電気・ガス",
+	Sector17TransportationLogistics:   "運輸・物流",
+	Sector17CommercialWholesale:       "商社・卸売",
+	Sector17Retail:                    "小売",
+	Sector17Banks:                     "銀行",
+	Sector17FinancialsExBanks:         "金融（除く銀行）",
+	Sector17RealEstate:                "不動産",
+	Sector17Other:                     "その他",
+}
+
+// Name は17業種の名称を返す。未知のコードなら空文字
+func (c Sector17Code) Name() string { return sector17Names[c] }
+
+// Sector33Code は33業種コード
+type Sector33Code string
+
+const (
+	Sector33Fishery                  Sector33Code = "0050"
+	Sector33Mining                   Sector33Code = "1050"
+	Sector33Construction             Sector33Code = "2050"
+	Sector33Foods                    Sector33Code = "3050"
+	Sector33Textiles                 Sector33Code = "3100"
+	Sector33PulpPaper                Sector33Code = "3150"
+	Sector33Chemicals                Sector33Code = "3200"
+	Sector33Pharmaceutical           Sector33Code = "3250"
+	Sector33OilCoal                  Sector33Code = "3300"
+	Sector33Rubber                   Sector33Code = "3350"
+	Sector33GlassCeramics            Sector33Code = "3400"
+	Sector33IronSteel                Sector33Code = "3450"
+	Sector33NonferrousMetals         Sector33Code = "3500"
+	Sector33MetalProducts            Sector33Code = "3550"
+	Sector33Machinery                Sector33Code = "3600"
+	Sector33ElectricAppliances       Sector33Code = "3650"
+	Sector33TransportEquipment       Sector33Code = "3700"
+	Sector33PrecisionInstruments     Sector33Code = "3750"
+	Sector33OtherProducts            Sector33Code = "3800"
+	Sector33ElectricPowerGas         Sector33Code = "4050"
+	Sector33LandTransportation       Sector33Code = "5050"
+	Sector33MarineTransportation     Sector33Code = "5100"
+	Sector33AirTransportation        Sector33Code = "5150"
+	Sector33WarehousingHarbor        Sector33Code = "5200"
+	Sector33InformationCommunication Sector33Code = "5250"
+	Sector33Wholesale                Sector33Code = "6050"
+	Sector33Retail                   Sector33Code = "6100"
+	Sector33Banks                    Sector33Code = "7050"
+	Sector33Securities               Sector33Code = "7100"
+	Sector33Insurance                Sector33Code = "7150"
+	Sector33OtherFinancing           Sector33Code = "7200"
+	Sector33RealEstate               Sector33Code = "8050"
+	Sector33Services                 Sector33Code = "9050"
+	Sector33Other                    Sector33Code = "9999"
+)
+
+var sector33Names = map[Sector33Code]string{
+	Sector33Fishery:                  "水産・農林業",
+	Sector33Mining:                   "鉱業",
+	Sector33Construction:             "建設業",
+	Sector33Foods:                    "食料品",
+	Sector33Textiles:                 "繊維製品",
+	Sector33PulpPaper:                "パルプ・紙",
+	Sector33Chemicals:                "化学",
+	Sector33Pharmaceutical:           "医薬品",
+	Sector33OilCoal:                  "石油・石炭製品",
+	Sector33Rubber:                   "ゴム製品",
+	Sector33GlassCeramics:            "ガラス・土石製品",
+	Sector33IronSteel:                "鉄鋼",
+	Sector33NonferrousMetals:         "非鉄金属",
+	Sector33MetalProducts:            "金属製品",
+	Sector33Machinery:                "機械",
+	Sector33ElectricAppliances:       "電気機器",
+	Sector33TransportEquipment:       "輸送用機器",
+	Sector33PrecisionInstruments:     "精密機器",
+	Sector33OtherProducts:            "その他製品",
+	Sector33ElectricPowerGas:         "電気・ガス業",
+	Sector33LandTransportation:       "陸運業",
+	Sector33MarineTransportation:     "海運業",
+	Sector33AirTransportation:        "空運業",
+	Sector33WarehousingHarbor:        "倉庫・運輸関連業",
+	Sector33InformationCommunication: "情報・通信業",
+	Sector33Wholesale:                "卸売業",
+	Sector33Retail:                   "小売業",
+	Sector33Banks:                    "銀行業",
+	Sector33Securities:               "証券、商品先物取引業",
+	Sector33Insurance:                "保険業",
+	Sector33OtherFinancing:           "その他金融業",
+	Sector33RealEstate:               "不動産業",
+	Sector33Services:                 "サービス業",
+	Sector33Other:                    "その他",
+}
+
+// Name は33業種の名称を返す。未知のコードなら空文字
+func (c Sector33Code) Name() string { return sector33Names[c] }
+
+// MarketCode は市場区分コード
+type MarketCode string
+
+const (
+	MarketTSE1st         MarketCode = "0101" // 東証一部 (2022/4 の市場再編まで)
+	MarketTSE2nd         MarketCode = "0102" // 東証二部 (同上)
+	MarketMothers        MarketCode = "0104" // マザーズ (同上)
+	MarketTokyoProMarket MarketCode = "0105"
+	MarketJASDAQStandard MarketCode = "0106" // (同上)
+	MarketJASDAQGrowth   MarketCode = "0107" // (同上)
+	MarketOther          MarketCode = "0109"
+	MarketPrime          MarketCode = "0111"
+	MarketStandard       MarketCode = "0112"
+	MarketGrowth         MarketCode = "0113"
+)
+
+var marketNames = map[MarketCode]string{
+	MarketTSE1st:         "東証一部",
+	MarketTSE2nd:         "東証二部",
+	MarketMothers:        "マザーズ",
+	MarketTokyoProMarket: "TOKYO PRO MARKET",
+	MarketJASDAQStandard: "JASDAQ スタンダード",
+	MarketJASDAQGrowth:   "JASDAQ グロース",
+	MarketOther:          "その他",
+	MarketPrime:          "プライム",
+	MarketStandard:       "スタンダード",
+	MarketGrowth:         "グロース",
+}
+
+// Name は市場区分の名称を返す。未知のコードなら空文字
+func (c MarketCode) Name() string { return marketNames[c] }
+
+// ScaleCategory は TOPIX の規模区分
+type ScaleCategory string
+
+const (
+	ScaleTOPIXCore30  ScaleCategory = "TOPIX Core30"
+	ScaleTOPIXLarge70 ScaleCategory = "TOPIX Large70"
+	ScaleTOPIXMid400  ScaleCategory = "TOPIX Mid400"
+	ScaleTOPIXSmall1  ScaleCategory = "TOPIX Small 1"
+	ScaleTOPIXSmall2  ScaleCategory = "TOPIX Small 2"
+	ScaleNone         ScaleCategory = "-" // TOPIX の対象外
+)
+
+// MarginCode は貸借信用区分
+type MarginCode string
+
+const (
+	MarginCodeMarginIssue MarginCode = "1" // 信用
+	MarginCodeLoanIssue   MarginCode = "2" // 貸借
+	MarginCodeOther       MarginCode = "3" // その他
+)
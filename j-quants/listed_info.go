@@ -0,0 +1,115 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// ListedInfo は /listed/info の1銘柄分の情報
+type ListedInfo struct {
+	Date               string        `json:"Date"`
+	Code               string        `json:"Code"`
+	CompanyName        string        `json:"CompanyName"`
+	CompanyNameEnglish string        `json:"CompanyNameEnglish"`
+	Sector17Code       Sector17Code  `json:"Sector17Code"`
+	Sector17CodeName   string        `json:"Sector17CodeName"`
+	Sector33Code       Sector33Code  `json:"Sector33Code"`
+	Sector33CodeName   string        `json:"Sector33CodeName"`
+	ScaleCategory      ScaleCategory `json:"ScaleCategory"`
+	MarketCode         MarketCode    `json:"MarketCode"`
+	MarketCodeName     string        `json:"MarketCodeName"`
+	MarginCode         MarginCode    `json:"MarginCode"`     // Standard / Premium プランのみ
+	MarginCodeName     string        `json:"MarginCodeName"` // Standard / Premium プランのみ
+}
+
+// listedInfoResponse : JSON全体を受け取るための構造
+type listedInfoResponse struct {
+	Info          []ListedInfo `json:"info"`
+	PaginationKey string       `json:"pagination_key"`
+}
+
+// GetListedInfoParams : クエリパラメータ。どちらも省略すると当日時点の全銘柄を返す
+type GetListedInfoParams struct {
+	Code string // 例: "86970" または "8697"
+	Date string // 例: "20230130" または "2023-01-30"。指定日時点の情報を返す
+}
+
+// GetListedInfo は /listed/info を全ページ取得し、[]ListedInfo を返す
+func (c *JQuantsClient) GetListedInfo(params GetListedInfoParams) ([]ListedInfo, error) {
+	return c.GetListedInfoWithContext(context.Background(), params)
+}
+
+// GetListedInfoWithContext は GetListedInfo の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetListedInfoWithContext(ctx context.Context, params GetListedInfoParams) ([]ListedInfo, error) {
+	return DoPaginatedGetWithContext[ListedInfo](ctx, c, c.endpoint("/listed/info"), params.values(), extractListedInfo)
+}
+
+// StreamListedInfo は /listed/info を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamListedInfo(ctx context.Context, params GetListedInfoParams, handle PageHandler[ListedInfo]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/listed/info"), params.values(), extractListedInfo, handle)
+}
+
+func (params GetListedInfoParams) values() url.Values {
+	q := url.Values{}
+	if params.Code != "" {
+		q.Set("code", params.Code)
+	}
+	if params.Date != "" {
+		q.Set("date", params.Date)
+	}
+	return q
+}
+
+func extractListedInfo(respBytes []byte) ([]ListedInfo, string, error) {
+	var r listedInfoResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal listed info: %w", err)
+	}
+	return r.Info, r.PaginationKey, nil
+}
+
+// FilterListedInfo は match が true を返す銘柄だけを返す
+func FilterListedInfo(infos []ListedInfo, match func(ListedInfo) bool) []ListedInfo {
+	var result []ListedInfo
+	for _, info := range infos {
+		if match(info) {
+			result = append(result, info)
+		}
+	}
+	return result
+}
+
+// InMarkets は指定した市場区分のいずれかに属する銘柄にマッチする条件を返す
+//
+//	prime := FilterListedInfo(infos, InMarkets(MarketPrime))
+func InMarkets(codes ...MarketCode) func(ListedInfo) bool {
+	return func(info ListedInfo) bool {
+		for _, code := range codes {
+			if info.MarketCode == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// GroupBySector17 は銘柄を17業種コードごとにまとめる
+func GroupBySector17(infos []ListedInfo) map[Sector17Code][]ListedInfo {
+	groups := make(map[Sector17Code][]ListedInfo)
+	for _, info := range infos {
+		groups[info.Sector17Code] = append(groups[info.Sector17Code], info)
+	}
+	return groups
+}
+
+// GroupBySector33 は銘柄を33業種コードごとにまとめる
+func GroupBySector33(infos []ListedInfo) map[Sector33Code][]ListedInfo {
+	groups := make(map[Sector33Code][]ListedInfo)
+	for _, info := range infos {
+		groups[info.Sector33Code] = append(groups[info.Sector33Code], info)
+	}
+	return groups
+}
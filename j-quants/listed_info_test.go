@@ -0,0 +1,48 @@
+package jquants
+
+import (
+	"net/http"
+	"testing"
+)
+
+const listedInfoJSON = `{"info":[
+	{"Date":"2024-01-04","Code":"72030","CompanyName":"トヨタ自動車","CompanyNameEnglish":"TOYOTA MOTOR CORPORATION","Sector17Code":"6","Sector17CodeName":"自動車・輸送機","Sector33Code":"3700","Sector33CodeName":"輸送用機器","ScaleCategory":"TOPIX Core30","MarketCode":"0111","MarketCodeName":"プライム"},
+	{"Date":"2024-01-04","Code":"91040","CompanyName":"商船三井","CompanyNameEnglish":"Mitsui O.S.K.Lines,Ltd.","Sector17Code":"12","Sector17CodeName":"運輸・物流","Sector33Code":"5100","Sector33CodeName":"海運業","ScaleCategory":"TOPIX Large70","MarketCode":"0111","MarketCodeName":"プライム"},
+	{"Date":"2024-01-04","Code":"41680","CompanyName":"ヤプリ","CompanyNameEnglish":"Yappli,Inc.","Sector17Code":"10","Sector17CodeName":"情報通信・サービスその他","Sector33Code":"5250","Sector33CodeName":"情報・通信業","ScaleCategory":"-","MarketCode":"0113","MarketCodeName":"グロース"}
+]}`
+
+func TestGetListedInfo(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/listed/info" || r.URL.Query().Get("date") != "2024-01-04" {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		w.Write([]byte(listedInfoJSON))
+	}))
+
+	infos, err := c.GetListedInfo(GetListedInfoParams{Date: "2024-01-04"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(infos))
+	}
+	if infos[0].Sector33Code != Sector33TransportEquipment || infos[0].Sector33Code.Name() != infos[0].Sector33CodeName {
+		t.Errorf("Unexpected sector: %+v", infos[0])
+	}
+	if infos[2].MarketCode.Name() != "グロース" || infos[2].ScaleCategory != ScaleNone {
+		t.Errorf("Unexpected market/scale: %+v", infos[2])
+	}
+
+	prime := FilterListedInfo(infos, InMarkets(MarketPrime))
+	if len(prime) != 2 {
+		t.Errorf("Expected 2 prime stocks, got %d", len(prime))
+	}
+	if groups := GroupBySector17(infos); len(groups[Sector17TransportationLogistics]) != 1 {
+		t.Errorf("Unexpected sector17 groups: %v", groups)
+	}
+	if groups := GroupBySector33(infos); len(groups) != 3 {
+		t.Errorf("Unexpected sector33 groups: %v", groups)
+	}
+}
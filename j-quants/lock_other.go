@@ -0,0 +1,8 @@
+//go:build !unix
+
+package jquants
+
+// lockFile は flock のない環境では何もしない。rename による置き換えだけで書き込みの破損は防げる
+func lockFile(path string, exclusive bool) (func(), error) {
+	return func() {}, nil
+}
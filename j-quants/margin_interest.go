@@ -0,0 +1,185 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// IssueType は銘柄の信用区分
+type IssueType string
+
+const (
+	IssueTypeMargin IssueType = "1" // 信用銘柄
+	IssueTypeLoan   IssueType = "2" // 貸借銘柄
+	IssueTypeOther  IssueType = "3" // その他
+)
+
+// WeeklyMarginInterest は /markets/weekly_margin_interest の1銘柄・1週分の信用取引残高 (株数)。
+// 値が無い場合は null や "-" で返ってくるので NullFloat64 で持つ
+type WeeklyMarginInterest struct {
+	Date                               string      `json:"Date"` // 申込日 (週末の営業日)
+	Code                               string      `json:"Code"`
+	ShortMarginTradeVolume             NullFloat64 `json:"ShortMarginTradeVolume"`
+	LongMarginTradeVolume              NullFloat64 `json:"LongMarginTradeVolume"`
+	ShortNegotiableMarginTradeVolume   NullFloat64 `json:"ShortNegotiableMarginTradeVolume"`
+	LongNegotiableMarginTradeVolume    NullFloat64 `json:"LongNegotiableMarginTradeVolume"`
+	ShortStandardizedMarginTradeVolume NullFloat64 `json:"ShortStandardizedMarginTradeVolume"`
+	LongStandardizedMarginTradeVolume  NullFloat64 `json:"LongStandardizedMarginTradeVolume"`
+	IssueType                          IssueType   `json:"IssueType"`
+}
+
+// MarginRatio は信用倍率 (買い残 / 売り残) を返す。どちらかが値なし、または売り残が 0 のときは false
+func (m WeeklyMarginInterest) MarginRatio() (float64, bool) {
+	return marginRatio(m.LongMarginTradeVolume, m.ShortMarginTradeVolume)
+}
+
+// weeklyMarginInterestResponse : JSON全体を受け取るための構造
+type weeklyMarginInterestResponse struct {
+	WeeklyMarginInterest []WeeklyMarginInterest `json:"weekly_margin_interest"`
+	PaginationKey        string                 `json:"pagination_key"`
+}
+
+// GetWeeklyMarginInterestParams : クエリパラメータ。Code か Date のどちらかは必須
+type GetWeeklyMarginInterestParams struct {
+	Code string
+	Date string
+	From string
+	To   string
+}
+
+// GetWeeklyMarginInterest は /markets/weekly_margin_interest を全ページ取得し、[]WeeklyMarginInterest を返す
+func (c *JQuantsClient) GetWeeklyMarginInterest(params GetWeeklyMarginInterestParams) ([]WeeklyMarginInterest, error) {
+	return c.GetWeeklyMarginInterestWithContext(context.Background(), params)
+}
+
+// GetWeeklyMarginInterestWithContext は GetWeeklyMarginInterest の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetWeeklyMarginInterestWithContext(ctx context.Context, params GetWeeklyMarginInterestParams) ([]WeeklyMarginInterest, error) {
+	return DoPaginatedGetWithContext[WeeklyMarginInterest](ctx, c, c.endpoint("/markets/weekly_margin_interest"), params.values(), extractWeeklyMarginInterest)
+}
+
+// StreamWeeklyMarginInterest は /markets/weekly_margin_interest を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamWeeklyMarginInterest(ctx context.Context, params GetWeeklyMarginInterestParams, handle PageHandler[WeeklyMarginInterest]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/markets/weekly_margin_interest"), params.values(), extractWeeklyMarginInterest, handle)
+}
+
+func (params GetWeeklyMarginInterestParams) values() url.Values {
+	return codeDateRangeValues(params.Code, params.Date, params.From, params.To)
+}
+
+func extractWeeklyMarginInterest(respBytes []byte) ([]WeeklyMarginInterest, string, error) {
+	var r weeklyMarginInterestResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal weekly_margin_interest: %w", err)
+	}
+	return r.WeeklyMarginInterest, r.PaginationKey, nil
+}
+
+// DailyMarginPublishReason は日々公表銘柄に指定された理由 ("1" なら該当)
+type DailyMarginPublishReason struct {
+	Restricted          string `json:"Restricted"`          // 信用取引の制限措置
+	DailyPublication    string `json:"DailyPublication"`    // 日々公表銘柄
+	Monitoring          string `json:"Monitoring"`          // 監視銘柄
+	RestrictedByJSF     string `json:"RestrictedByJSF"`     // 日証金の申込制限
+	PrecautionByJSF     string `json:"PrecautionByJSF"`     // 日証金の注意喚起
+	UnclearOrSecOnAlert string `json:"UnclearOrSecOnAlert"` // 不明確・特別注意銘柄
+}
+
+// DailyMarginInterest は /markets/daily_margin_interest の1銘柄・1日分の信用取引残高 (日々公表銘柄のみ)。
+// 数値は WeeklyMarginInterest と同じく NullFloat64 で持つ
+type DailyMarginInterest struct {
+	PublishedDate                                        string                   `json:"PublishedDate"`
+	Code                                                 string                   `json:"Code"`
+	ApplicationDate                                      string                   `json:"ApplicationDate"`
+	PublishReason                                        DailyMarginPublishReason `json:"PublishReason"`
+	ShortMarginOutstanding                               NullFloat64              `json:"ShortMarginOutstanding"`
+	DailyChangeShortMarginOutstanding                    NullFloat64              `json:"DailyChangeShortMarginOutstanding"`
+	ShortMarginOutstandingListedShareRatio               NullFloat64              `json:"ShortMarginOutstandingListedShareRatio"`
+	LongMarginOutstanding                                NullFloat64              `json:"LongMarginOutstanding"`
+	DailyChangeLongMarginOutstanding                     NullFloat64              `json:"DailyChangeLongMarginOutstanding"`
+	LongMarginOutstandingListedShareRatio                NullFloat64              `json:"LongMarginOutstandingListedShareRatio"`
+	ShortLongRatio                                       NullFloat64              `json:"ShortLongRatio"`
+	ShortNegotiableMarginOutstanding                     NullFloat64              `json:"ShortNegotiableMarginOutstanding"`
+	DailyChangeShortNegotiableMarginOutstanding          NullFloat64              `json:"DailyChangeShortNegotiableMarginOutstanding"`
+	ShortStandardizedMarginOutstanding                   NullFloat64              `json:"ShortStandardizedMarginOutstanding"`
+	DailyChangeShortStandardizedMarginOutstanding        NullFloat64              `json:"DailyChangeShortStandardizedMarginOutstanding"`
+	LongNegotiableMarginOutstanding                      NullFloat64              `json:"LongNegotiableMarginOutstanding"`
+	DailyChangeLongNegotiableMarginOutstanding           NullFloat64              `json:"DailyChangeLongNegotiableMarginOutstanding"`
+	LongStandardizedMarginOutstanding                    NullFloat64              `json:"LongStandardizedMarginOutstanding"`
+	DailyChangeLongStandardizedMarginOutstanding         NullFloat64              `json:"DailyChangeLongStandardizedMarginOutstanding"`
+	TSEMarginBorrowingAndLendingRegulationClassification string                   `json:"TSEMarginBorrowingAndLendingRegulationClassification"`
+}
+
+// MarginRatio は信用倍率 (買い残 / 売り残) を返す。どちらかが値なし、または売り残が 0 のときは false
+func (m DailyMarginInterest) MarginRatio() (float64, bool) {
+	return marginRatio(m.LongMarginOutstanding, m.ShortMarginOutstanding)
+}
+
+func marginRatio(long, short NullFloat64) (float64, bool) {
+	if !long.Valid || !short.Valid || short.Float64 == 0 {
+		return 0, false
+	}
+	return long.Float64 / short.Float64, true
+}
+
+// dailyMarginInterestResponse : JSON全体を受け取るための構造
+type dailyMarginInterestResponse struct {
+	DailyMarginInterest []DailyMarginInterest `json:"daily_margin_interest"`
+	PaginationKey       string                `json:"pagination_key"`
+}
+
+// GetDailyMarginInterestParams : クエリパラメータ。Code か Date のどちらかは必須
+type GetDailyMarginInterestParams struct {
+	Code string
+	Date string
+	From string
+	To   string
+}
+
+// GetDailyMarginInterest は /markets/daily_margin_interest を全ページ取得し、[]DailyMarginInterest を返す
+func (c *JQuantsClient) GetDailyMarginInterest(params GetDailyMarginInterestParams) ([]DailyMarginInterest, error) {
+	return c.GetDailyMarginInterestWithContext(context.Background(), params)
+}
+
+// GetDailyMarginInterestWithContext は GetDailyMarginInterest の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetDailyMarginInterestWithContext(ctx context.Context, params GetDailyMarginInterestParams) ([]DailyMarginInterest, error) {
+	return DoPaginatedGetWithContext[DailyMarginInterest](ctx, c, c.endpoint("/markets/daily_margin_interest"), params.values(), extractDailyMarginInterest)
+}
+
+// StreamDailyMarginInterest は /markets/daily_margin_interest を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamDailyMarginInterest(ctx context.Context, params GetDailyMarginInterestParams, handle PageHandler[DailyMarginInterest]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/markets/daily_margin_interest"), params.values(), extractDailyMarginInterest, handle)
+}
+
+func (params GetDailyMarginInterestParams) values() url.Values {
+	return codeDateRangeValues(params.Code, params.Date, params.From, params.To)
+}
+
+func extractDailyMarginInterest(respBytes []byte) ([]DailyMarginInterest, string, error) {
+	var r dailyMarginInterestResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal daily_margin_interest: %w", err)
+	}
+	return r.DailyMarginInterest, r.PaginationKey, nil
+}
+
+// codeDateRangeValues は code / date / from / to の4つを取るエンドポイント共通のクエリを作る
+func codeDateRangeValues(code, date, from, to string) url.Values {
+	q := url.Values{}
+	if code != "" {
+		q.Set("code", code)
+	}
+	if date != "" {
+		q.Set("date", date)
+	}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	return q
+}
@@ -0,0 +1,92 @@
+package jquants
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetWeeklyMarginInterest(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/markets/weekly_margin_interest" || q.Get("code") != "7203" || q.Get("from") != "2024-01-05" || q.Get("to") != "2024-01-12" || q.Has("date") {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		switch q.Get("pagination_key") {
+		case "":
+			w.Write([]byte(`{"weekly_margin_interest":[{"Date":"2024-01-05","Code":"72030","ShortMarginTradeVolume":1000.0,"LongMarginTradeVolume":3000.0,"ShortNegotiableMarginTradeVolume":400.0,"LongNegotiableMarginTradeVolume":1000.0,"ShortStandardizedMarginTradeVolume":600.0,"LongStandardizedMarginTradeVolume":2000.0,"IssueType":"2"}],"pagination_key":"next"}`))
+		case "next":
+			w.Write([]byte(`{"weekly_margin_interest":[{"Date":"2024-01-12","Code":"72030","ShortMarginTradeVolume":"-","LongMarginTradeVolume":2500.0,"ShortNegotiableMarginTradeVolume":null,"LongNegotiableMarginTradeVolume":null,"ShortStandardizedMarginTradeVolume":null,"LongStandardizedMarginTradeVolume":null,"IssueType":"2"}]}`))
+		default:
+			t.Errorf("Unexpected pagination key: %s", q.Get("pagination_key"))
+		}
+	}))
+
+	rows, err := c.GetWeeklyMarginInterest(GetWeeklyMarginInterestParams{Code: "7203", From: "2024-01-05", To: "2024-01-12"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(rows))
+	}
+	if rows[0].IssueType != IssueTypeLoan || rows[0].LongStandardizedMarginTradeVolume != Float(2000) {
+		t.Errorf("Unexpected record: %+v", rows[0])
+	}
+	// "-" は 0 ではなく値なしになる
+	if rows[1].ShortMarginTradeVolume.Valid || rows[1].LongMarginTradeVolume != Float(2500) {
+		t.Errorf("Unexpected record: %+v", rows[1])
+	}
+}
+
+func TestGetDailyMarginInterest(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/markets/daily_margin_interest" || q.Get("date") != "2024-01-05" || q.Has("code") {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		switch q.Get("pagination_key") {
+		case "":
+			w.Write([]byte(`{"daily_margin_interest":[{"PublishedDate":"2024-01-05","Code":"13260","ApplicationDate":"2024-01-04","PublishReason":{"Restricted":"0","DailyPublication":"1","Monitoring":"0","RestrictedByJSF":"0","PrecautionByJSF":"0","UnclearOrSecOnAlert":"0"},"ShortMarginOutstanding":2000.0,"DailyChangeShortMarginOutstanding":-100.0,"ShortMarginOutstandingListedShareRatio":0.1,"LongMarginOutstanding":5000.0,"DailyChangeLongMarginOutstanding":200.0,"LongMarginOutstandingListedShareRatio":0.25,"ShortLongRatio":40.0,"TSEMarginBorrowingAndLendingRegulationClassification":"001"}],"pagination_key":"next"}`))
+		case "next":
+			w.Write([]byte(`{"daily_margin_interest":[{"PublishedDate":"2024-01-05","Code":"25930","ApplicationDate":"2024-01-04","PublishReason":{"Restricted":"1"},"ShortMarginOutstanding":"-","LongMarginOutstanding":1000.0,"ShortLongRatio":"-"}]}`))
+		default:
+			t.Errorf("Unexpected pagination key: %s", q.Get("pagination_key"))
+		}
+	}))
+
+	rows, err := c.GetDailyMarginInterest(GetDailyMarginInterestParams{Date: "2024-01-05"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(rows))
+	}
+	if rows[0].PublishReason.DailyPublication != "1" || rows[0].DailyChangeShortMarginOutstanding != Float(-100) {
+		t.Errorf("Unexpected record: %+v", rows[0])
+	}
+	if ratio, ok := rows[0].MarginRatio(); !ok || ratio != 2.5 {
+		t.Errorf("Expected margin ratio 2.5, got %v (ok=%v)", ratio, ok)
+	}
+	if rows[1].ShortMarginOutstanding.Valid || rows[1].ShortLongRatio.Valid {
+		t.Errorf("Expected missing values to be invalid: %+v", rows[1])
+	}
+	if _, ok := rows[1].MarginRatio(); ok {
+		t.Error("Expected no margin ratio without short margin outstanding")
+	}
+}
+
+func TestMarginRatio(t *testing.T) {
+	m := WeeklyMarginInterest{LongMarginTradeVolume: Float(3000), ShortMarginTradeVolume: Float(1000)}
+	if ratio, ok := m.MarginRatio(); !ok || ratio != 3 {
+		t.Errorf("Expected margin ratio 3, got %v (ok=%v)", ratio, ok)
+	}
+	if _, ok := (WeeklyMarginInterest{LongMarginTradeVolume: Float(3000), ShortMarginTradeVolume: Float(0)}).MarginRatio(); ok {
+		t.Error("Expected no margin ratio without short margin")
+	}
+	if _, ok := (WeeklyMarginInterest{LongMarginTradeVolume: Float(3000)}).MarginRatio(); ok {
+		t.Error("Expected no margin ratio when short margin is missing")
+	}
+}
@@ -0,0 +1,214 @@
+package jquants
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nullString は JSON の値を文字列として取り出す。null は空文字として返し、数値などのリテラルはそのまま返す
+func nullString(b []byte) (string, error) {
+	b = bytes.TrimSpace(b)
+	if string(b) == "null" {
+		return "", nil
+	}
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return "", err
+		}
+	}
+	return s, nil
+}
+
+// NullFloat64 は値が無いことがある数値。J-Quants は値が無いときに null, "", "-" のいずれかを返すので、
+// それらはすべて Valid = false として扱う。数値は JSON の数値でも文字列でも受け付ける
+type NullFloat64 struct {
+	Float64 float64
+	Valid   bool
+}
+
+// Float は v の値を持つ NullFloat64 を返す
+func Float(v float64) NullFloat64 {
+	return NullFloat64{Float64: v, Valid: true}
+}
+
+func (n *NullFloat64) UnmarshalJSON(b []byte) error {
+	s, err := nullString(b)
+	if err != nil {
+		return err
+	}
+	v, err := parseNullFloat64(s)
+	if err != nil {
+		return err
+	}
+	*n = v
+	return nil
+}
+
+func (n NullFloat64) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Float64)
+}
+
+// parseNullFloat64 は文字列の数値を読む。空文字と "-" は値なしとする
+func parseNullFloat64(s string) (NullFloat64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "-" {
+		return NullFloat64{}, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return NullFloat64{}, fmt.Errorf("invalid number: %q", s)
+	}
+	return Float(v), nil
+}
+
+func (n NullFloat64) String() string {
+	if !n.Valid {
+		return "-"
+	}
+	return strconv.FormatFloat(n.Float64, 'f', -1, 64)
+}
+
+// NullInt64 は値が無いことがある整数。金額 (円) や株数のように桁の大きい値を誤差なく持つために使う
+type NullInt64 struct {
+	Int64 int64
+	Valid bool
+}
+
+// Int は v の値を持つ NullInt64 を返す
+func Int(v int64) NullInt64 {
+	return NullInt64{Int64: v, Valid: true}
+}
+
+func (n *NullInt64) UnmarshalJSON(b []byte) error {
+	s, err := nullString(b)
+	if err != nil {
+		return err
+	}
+	v, err := parseNullInt64(s)
+	if err != nil {
+		return err
+	}
+	*n = v
+	return nil
+}
+
+func (n NullInt64) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Int64)
+}
+
+// parseNullInt64 は文字列の整数を読む。空文字と "-" は値なしとする。
+// 小数部があるなど整数として読めない値は、丸めずにエラーにする
+func parseNullInt64(s string) (NullInt64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "-" {
+		return NullInt64{}, nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return NullInt64{}, fmt.Errorf("invalid integer: %q", s)
+	}
+	return Int(v), nil
+}
+
+func (n NullInt64) String() string {
+	if !n.Valid {
+		return "-"
+	}
+	return strconv.FormatInt(n.Int64, 10)
+}
+
+// NullBool は値が無いことがある真偽値。J-Quants は "true" / "false" か "1" / "0" の文字列で返す
+type NullBool struct {
+	Bool  bool
+	Valid bool
+}
+
+func (n *NullBool) UnmarshalJSON(b []byte) error {
+	s, err := nullString(b)
+	if err != nil {
+		return err
+	}
+	v, err := parseNullBool(s)
+	if err != nil {
+		return err
+	}
+	*n = v
+	return nil
+}
+
+func (n NullBool) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Bool)
+}
+
+// parseNullBool は "true" / "false" (大文字小文字は区別しない) と "1" / "0" を読む。空文字と "-" は値なしとする
+func parseNullBool(s string) (NullBool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "-":
+		return NullBool{}, nil
+	case "true", "1":
+		return NullBool{Bool: true, Valid: true}, nil
+	case "false", "0":
+		return NullBool{Bool: false, Valid: true}, nil
+	}
+	return NullBool{}, fmt.Errorf("invalid bool: %q", s)
+}
+
+// NullDate は値が無いことがある日付。JST の0時として持つ
+type NullDate struct {
+	Time  time.Time
+	Valid bool
+}
+
+func (n *NullDate) UnmarshalJSON(b []byte) error {
+	s, err := nullString(b)
+	if err != nil {
+		return err
+	}
+	v, err := parseNullDate(s)
+	if err != nil {
+		return err
+	}
+	*n = v
+	return nil
+}
+
+func (n NullDate) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Time.Format(dateLayout))
+}
+
+// parseNullDate は "2006-01-02" または "20060102" 形式の日付を読む。空文字と "-" は値なしとする
+func parseNullDate(s string) (NullDate, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "-" {
+		return NullDate{}, nil
+	}
+	t, err := ParseDate(s)
+	if err != nil {
+		return NullDate{}, err
+	}
+	return NullDate{Time: t, Valid: true}, nil
+}
+
+func (n NullDate) String() string {
+	if !n.Valid {
+		return "-"
+	}
+	return n.Time.Format(dateLayout)
+}
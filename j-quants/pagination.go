@@ -1,8 +1,9 @@
 package jquants
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"net/url"
@@ -13,19 +14,62 @@ type PageDataExtractor[T any] func(respBytes []byte) ([]T, string, error)
 
 // DoPaginatedGet は、ページネーション付きの GET リクエストを行い、すべてのページを取得して []T を返す汎用関数。
 func DoPaginatedGet[T any](
-	c *JQuantsClient,    // トークン管理・認証のためのクライアント
-	baseURL string,      // 例: "https://api.jquants.com/v1/prices/daily_quotes"
-	params url.Values,   // クエリパラメータ
+	c *JQuantsClient, // トークン管理・認証のためのクライアント
+	baseURL string, // 例: "https://api.jquants.com/v1/prices/daily_quotes"
+	params url.Values, // クエリパラメータ
 	extract PageDataExtractor[T], // JSONをどうパースして dataとpagination_keyを取り出すか
 ) ([]T, error) {
+	return DoPaginatedGetWithContext(context.Background(), c, baseURL, params, extract)
+}
+
+// DoPaginatedGetWithContext は DoPaginatedGet の context 対応版。
+// ctx がキャンセルされるとページ取得を中断し、それまでに取得できたデータとエラーを返す。
+func DoPaginatedGetWithContext[T any](
+	ctx context.Context,
+	c *JQuantsClient,
+	baseURL string,
+	params url.Values,
+	extract PageDataExtractor[T],
+) ([]T, error) {
 
 	var result []T
+	err := DoPaginatedEach(ctx, c, baseURL, params, extract, func(page []T) error {
+		result = append(result, page...)
+		return nil
+	})
+	return result, err
+}
+
+// PageHandler は1ページ分のデータを受け取るコールバック。
+// ErrStopPagination を返すと残りのページを取得せずに正常終了する
+type PageHandler[T any] func(page []T) error
+
+// ErrStopPagination は PageHandler から返すことで、ページ取得を途中でやめるためのエラー
+var ErrStopPagination = errors.New("jquants: stop pagination")
+
+// DoPaginatedEach は、ページネーション付きの GET リクエストを行い、1ページ取得するごとに handle を呼ぶ。
+// 全件をメモリに溜めないので、大量のデータをそのまま保存先へ流す場合に使う
+func DoPaginatedEach[T any](
+	ctx context.Context,
+	c *JQuantsClient,
+	baseURL string,
+	params url.Values,
+	extract PageDataExtractor[T],
+	handle PageHandler[T],
+) error {
+
 	var paginationKey string
+	var endpoint string
+	if c.cache != nil {
+		if u, err := url.Parse(baseURL); err == nil {
+			endpoint = c.endpointName(u)
+		}
+	}
 
 	for {
-		// 1. トークンが期限切れであれば更新
-		if err := c.ensureToken(); err != nil {
-			return nil, fmt.Errorf("failed to ensure token: %w", err)
+		// 1. キャンセル済みであれば次のページへ進まない
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
 		// 2. pagination_key の指定
@@ -34,49 +78,75 @@ func DoPaginatedGet[T any](
 		} else {
 			params.Del("pagination_key")
 		}
-
 		fullURL := baseURL + "?" + params.Encode()
-		log.Printf("[INFO] GET => %s", fullURL)
-
-		// 3. HTTPリクエスト
-		req, err := http.NewRequest("GET", fullURL, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-		req.Header.Set("Authorization", "Bearer "+c.IDToken)
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to do request: %w", err)
-		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return nil, fmt.Errorf("request failed: status=%d, body=%s", resp.StatusCode, string(body))
+		// 3. キャッシュにあればそれを使う。無ければトークンを確認して HTTPリクエストを送る
+		var respBytes []byte
+		var cached bool
+		if c.cache != nil {
+			respBytes, cached = c.cache.get(ctx, c.cacheScope(), endpoint, params)
 		}
-
-		// 4. レスポンスを読み取り
-		respBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response: %w", err)
+		if !cached {
+			if err := c.ensureToken(ctx); err != nil {
+				return fmt.Errorf("failed to ensure token: %w", err)
+			}
+			var err error
+			if respBytes, err = c.getPage(ctx, fullURL); err != nil {
+				return err
+			}
 		}
 
-		// 5. コールバックで dataPart と nextKey を抽出
+		// 4. コールバックで dataPart と nextKey を抽出
 		dataPart, next, err := extract(respBytes)
 		if err != nil {
-			return nil, fmt.Errorf("failed to extract page data: %w", err)
+			return fmt.Errorf("failed to extract page data: %w", err)
+		}
+		// 抽出できたレスポンスだけをキャッシュする
+		if c.cache != nil && !cached {
+			c.cache.put(c.cacheScope(), endpoint, params, fullURL, respBytes, len(dataPart) == 0)
 		}
 
-		result = append(result, dataPart...)
+		// 5. 呼び出し側にページを渡す
+		if err := handle(dataPart); err != nil {
+			if errors.Is(err, ErrStopPagination) {
+				return nil
+			}
+			return err
+		}
 
 		// 6. pagination_key が空なら終了
 		if next == "" {
-			break
+			return nil
 		}
 		paginationKey = next
 	}
+}
+
+// getPage は認証付きの GET リクエストを送り、レスポンスボディを返す。
+// 401 が返った場合はトークンを取り直して1回だけ再送する
+func (c *JQuantsClient) getPage(ctx context.Context, fullURL string) ([]byte, error) {
+	log.Printf("[INFO] GET => %s", fullURL)
 
-	return result, nil
+	idToken := c.Tokens().IDToken
+	body, err := c.getAuthorized(ctx, fullURL, idToken)
+	if !hasStatus(err, http.StatusUnauthorized) {
+		return body, err
+	}
+
+	log.Println("[WARN] Got 401 Unauthorized. Refreshing token and retrying once.")
+	if refreshErr := c.forceRefresh(ctx, idToken); refreshErr != nil {
+		return nil, fmt.Errorf("failed to recover from 401: %w", errors.Join(refreshErr, err))
+	}
+	return c.getAuthorized(ctx, fullURL, c.Tokens().IDToken)
+}
+
+func (c *JQuantsClient) getAuthorized(ctx context.Context, fullURL, idToken string) ([]byte, error) {
+	return c.do(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+idToken)
+		return req, nil
+	})
 }
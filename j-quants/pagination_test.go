@@ -0,0 +1,83 @@
+package jquants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDoPaginatedGetWithContextReturnsPartialResult(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pages := 0
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		if pages == 1 {
+			fmt.Fprint(w, `{"daily_quotes":[{"Date":"2024-01-04","Code":"72030"}],"pagination_key":"key1"}`)
+			return
+		}
+		// 2ページ目の取得中に呼び出し側がキャンセルする
+		cancel()
+		<-r.Context().Done()
+	}))
+
+	quotes, err := c.GetDailyQuotesWithContext(ctx, GetDailyQuotesParams{Code: "7203"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].Date.String() != "2024-01-04" {
+		t.Errorf("Expected the first page to be returned, got: %+v", quotes)
+	}
+	if pages != 2 {
+		t.Errorf("Expected pagination to stop at the 2nd page, got %d requests", pages)
+	}
+}
+
+func TestStreamDailyQuotesStopsEarly(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		fmt.Fprintf(w, `{"daily_quotes":[{"Date":"2024-01-0%d","Code":"72030"}],"pagination_key":"key%d"}`, n, n)
+	}))
+
+	var seen []string
+	err := c.StreamDailyQuotes(context.Background(), GetDailyQuotesParams{Code: "7203"}, func(page []DailyQuote) error {
+		for _, q := range page {
+			seen = append(seen, q.Date.String())
+		}
+		if len(seen) == 2 {
+			return ErrStopPagination
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected early stop to return nil, got: %v", err)
+	}
+	if len(seen) != 2 || requests.Load() != 2 {
+		t.Errorf("Expected to stop after 2 pages, got rows=%v requests=%d", seen, requests.Load())
+	}
+}
+
+func TestStreamDailyQuotesPropagatesHandlerError(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"daily_quotes":[{"Date":"2024-01-04","Code":"72030"}],"pagination_key":"next"}`)
+	}))
+
+	wantErr := errors.New("disk full")
+	err := c.StreamDailyQuotes(context.Background(), GetDailyQuotesParams{Code: "7203"}, func(page []DailyQuote) error {
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Expected handler error, got: %v", err)
+	}
+}
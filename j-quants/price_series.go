@@ -0,0 +1,277 @@
+package jquants
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PriceBar は1日分の四本値と出来高。約定が無かった日は値なし
+type PriceBar struct {
+	Date   Date
+	Open   NullFloat64
+	High   NullFloat64
+	Low    NullFloat64
+	Close  NullFloat64
+	Volume NullFloat64
+}
+
+// scale は価格に priceFactor を掛け、出来高を priceFactor で割った PriceBar を返す
+func (b PriceBar) scale(priceFactor float64) PriceBar {
+	mul := func(v NullFloat64) NullFloat64 {
+		if !v.Valid {
+			return v
+		}
+		return Float(v.Float64 * priceFactor)
+	}
+	scaled := PriceBar{Date: b.Date, Open: mul(b.Open), High: mul(b.High), Low: mul(b.Low), Close: mul(b.Close)}
+	if b.Volume.Valid {
+		scaled.Volume = Float(b.Volume.Float64 / priceFactor)
+	}
+	return scaled
+}
+
+// CorporateAction は株式分割・併合などで調整係数が 1 でなかった日
+type CorporateAction struct {
+	Date   Date
+	Factor float64 // その日より前の価格に掛ける係数。1:2 の分割なら 0.5
+}
+
+// IsSplit は株式分割かどうかを返す
+func (a CorporateAction) IsSplit() bool {
+	return a.Factor < 1
+}
+
+// IsReverseSplit は株式併合かどうかを返す
+func (a CorporateAction) IsReverseSplit() bool {
+	return a.Factor > 1
+}
+
+// PriceSeries は1銘柄の日足を日付順に並べたもの。
+// 調整後の値は J-Quants の Adjustment* をそのまま使わず、生の値と日々の調整係数から計算し直す。
+// Adjustment* は取得した時点の最新を基準にしているため、取得時期の異なるデータを繋ぐと基準がずれるため
+type PriceSeries struct {
+	Code string
+
+	quotes []DailyQuote // 日付の昇順
+	// cumulative[i] は quotes[i] の価格に掛ける累積の調整係数 (i より後の調整係数の積)
+	cumulative []float64
+	dividends  map[string]float64 // 権利落ち日 ("2006-01-02") → 1株あたり配当 (円)
+}
+
+// NewPriceSeries は1銘柄分の日足から PriceSeries を作る。並び順は問わず、同じ日付は後のものを優先する
+func NewPriceSeries(quotes []DailyQuote) (*PriceSeries, error) {
+	s := &PriceSeries{}
+	if _, err := s.Append(quotes); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Len は日数を返す
+func (s *PriceSeries) Len() int {
+	return len(s.quotes)
+}
+
+// Quotes は日付順の日足を返す。AdjustmentFactor は欠けていた場合も補完済み (常に Valid)
+func (s *PriceSeries) Quotes() []DailyQuote {
+	return append([]DailyQuote(nil), s.quotes...)
+}
+
+// Raw は調整前の値を返す
+func (s *PriceSeries) Raw() []PriceBar {
+	bars := make([]PriceBar, len(s.quotes))
+	for i, q := range s.quotes {
+		bars[i] = rawBar(q)
+	}
+	return bars
+}
+
+// Adjusted は株式分割・併合を調整した値を返す。最新日の価格が基準になる
+func (s *PriceSeries) Adjusted() []PriceBar {
+	bars := make([]PriceBar, len(s.quotes))
+	for i, q := range s.quotes {
+		bars[i] = rawBar(q).scale(s.cumulative[i])
+	}
+	return bars
+}
+
+// Factors は各日の価格に掛ける累積の調整係数を返す。Adjusted の値は Raw にこの係数を掛けたもの
+func (s *PriceSeries) Factors() []float64 {
+	return append([]float64(nil), s.cumulative...)
+}
+
+// SetDividends は DividendAdjusted で使う配当を設定する。
+// 予想や取り消された配当は使わず、訂正は最新の通知だけを使う
+func (s *PriceSeries) SetDividends(divs []CashDividend) {
+	s.dividends = make(map[string]float64)
+	for _, d := range LatestDividends(divs) {
+		if d.IsForecast() || !d.GrossDividendRate.Valid || d.GrossDividendRate.Float64 <= 0 {
+			continue
+		}
+		if ex, err := ParseDate(d.ExDate); err == nil {
+			s.dividends[DateOf(ex).String()] += d.GrossDividendRate.Float64
+		}
+	}
+}
+
+// DividendAdjusted は分割・併合に加えて配当も調整した値 (トータルリターン用) を返す。
+// 権利落ち日より前の価格に (前日終値 - 配当) / 前日終値 を掛ける。前日終値が無い配当は無視する
+func (s *PriceSeries) DividendAdjusted() []PriceBar {
+	bars := make([]PriceBar, len(s.quotes))
+	divFactor := 1.0
+	for i := len(s.quotes) - 1; i >= 0; i-- {
+		bars[i] = rawBar(s.quotes[i]).scale(s.cumulative[i] * divFactor)
+		div, ok := s.dividends[s.quotes[i].Date.String()]
+		if !ok {
+			continue
+		}
+		if prev, ok := s.prevClose(i); ok && div < prev {
+			divFactor *= (prev - div) / prev
+		}
+	}
+	return bars
+}
+
+// prevClose は i より前で最後に約定のあった日の終値を返す
+func (s *PriceSeries) prevClose(i int) (float64, bool) {
+	for i--; i >= 0; i-- {
+		if !s.quotes[i].Halted() {
+			return s.quotes[i].Close.Float64, true
+		}
+	}
+	return 0, false
+}
+
+// CorporateActions は調整係数が 1 でなかった日を日付順に返す
+func (s *PriceSeries) CorporateActions() []CorporateAction {
+	var actions []CorporateAction
+	for _, q := range s.quotes {
+		if q.AdjustmentFactor.Float64 != 1 {
+			actions = append(actions, CorporateAction{Date: q.Date, Factor: q.AdjustmentFactor.Float64})
+		}
+	}
+	return actions
+}
+
+// Append は新しい日足を取り込み、調整後の値を更新する。既存の日付と重なる日足は置き換える。
+// 新しい日足がすべて既存の最終日より後なら、既存の累積係数に新しい調整係数を掛けるだけで済ませる。
+// 戻り値は既存の日の累積調整係数が変わったかどうか
+func (s *PriceSeries) Append(quotes []DailyQuote) (bool, error) {
+	if len(quotes) == 0 {
+		return false, nil
+	}
+	incoming := append([]DailyQuote(nil), quotes...)
+	sort.SliceStable(incoming, func(i, j int) bool { return incoming[i].Date.Before(incoming[j].Date.Time) })
+	for _, q := range incoming {
+		if s.Code == "" {
+			s.Code = q.Code
+		}
+		if q.Code != s.Code {
+			return false, fmt.Errorf("price series for %s: got a quote for %s", s.Code, q.Code)
+		}
+	}
+	incoming = dedupeByDate(incoming)
+
+	if n := len(s.quotes); n == 0 || incoming[0].Date.After(s.quotes[n-1].Date.Time) {
+		return s.extend(incoming), nil
+	}
+
+	// 過去分の差し替えを含む場合は全体を作り直す
+	byDate := make(map[string]DailyQuote, len(s.quotes)+len(incoming))
+	for _, q := range s.quotes {
+		byDate[q.Date.String()] = q
+	}
+	for _, q := range incoming {
+		byDate[q.Date.String()] = q
+	}
+	merged := make([]DailyQuote, 0, len(byDate))
+	for _, q := range byDate {
+		merged = append(merged, q)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Date.Before(merged[j].Date.Time) })
+
+	before := make(map[string]float64, len(s.quotes))
+	for i, q := range s.quotes {
+		before[q.Date.String()] = s.cumulative[i]
+	}
+	s.quotes, s.cumulative = nil, nil
+	s.extend(merged)
+
+	changed := false
+	for i, q := range s.quotes {
+		if f, ok := before[q.Date.String()]; ok && math.Abs(f-s.cumulative[i]) > 1e-12*math.Abs(f) {
+			changed = true
+			break
+		}
+	}
+	return changed, nil
+}
+
+// dedupeByDate は日付順に並んだ日足から同じ日付の重複を除く。重複した日付は後のものを残す
+func dedupeByDate(quotes []DailyQuote) []DailyQuote {
+	result := quotes[:0]
+	for _, q := range quotes {
+		if n := len(result); n > 0 && result[n-1].Date.Equal(q.Date.Time) {
+			result[n-1] = q
+			continue
+		}
+		result = append(result, q)
+	}
+	return result
+}
+
+// extend は最終日より後の日足 (日付順で重複なし) を末尾に足す。既存の日の係数が変わったら true を返す
+func (s *PriceSeries) extend(quotes []DailyQuote) bool {
+	changed := false
+	for _, q := range quotes {
+		var prev *DailyQuote
+		if n := len(s.quotes); n > 0 {
+			prev = &s.quotes[n-1]
+		}
+		f := adjustmentFactor(prev, q)
+		q.AdjustmentFactor = Float(f)
+		if f != 1 {
+			for i := range s.cumulative {
+				s.cumulative[i] *= f
+			}
+			changed = changed || len(s.cumulative) > 0
+		}
+		s.quotes = append(s.quotes, q)
+		s.cumulative = append(s.cumulative, 1)
+	}
+	return changed
+}
+
+// adjustmentFactor は q の調整係数を返す。AdjustmentFactor が欠けている (null か 0 以下) 場合は、
+// 前日と当日の Adjustment* / 生の終値の比から復元する。丸め誤差とみなせる差は 1 とする
+func adjustmentFactor(prev *DailyQuote, q DailyQuote) float64 {
+	if q.AdjustmentFactor.Valid && q.AdjustmentFactor.Float64 > 0 {
+		return q.AdjustmentFactor.Float64
+	}
+	if prev == nil {
+		return 1
+	}
+	prevRatio, ok1 := adjustmentRatio(*prev)
+	ratio, ok2 := adjustmentRatio(q)
+	if !ok1 || !ok2 {
+		return 1
+	}
+	f := prevRatio / ratio
+	if math.Abs(f-1) < 1e-3 {
+		return 1
+	}
+	return math.Round(f*1e6) / 1e6
+}
+
+// adjustmentRatio は Adjustment* の終値と生の終値の比を返す
+func adjustmentRatio(q DailyQuote) (float64, bool) {
+	if !q.Close.Valid || !q.AdjustmentClose.Valid || q.Close.Float64 == 0 {
+		return 0, false
+	}
+	return q.AdjustmentClose.Float64 / q.Close.Float64, true
+}
+
+func rawBar(q DailyQuote) PriceBar {
+	return PriceBar{Date: q.Date, Open: q.Open, High: q.High, Low: q.Low, Close: q.Close, Volume: q.Volume}
+}
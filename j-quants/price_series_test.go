@@ -0,0 +1,159 @@
+package jquants
+
+import (
+	"math"
+	"testing"
+)
+
+func quote(date string, close, factor float64) DailyQuote {
+	return DailyQuote{Date: DateOf(day(date)), Code: "72030", Close: Float(close), Volume: Float(1000), AdjustmentFactor: Float(factor)}
+}
+
+func closes(bars []PriceBar) []float64 {
+	result := make([]float64, len(bars))
+	for i, b := range bars {
+		result[i] = b.Close.Float64
+	}
+	return result
+}
+
+func approxSlice(got, want []float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPriceSeriesAdjustsSplits(t *testing.T) {
+	s, err := NewPriceSeries([]DailyQuote{
+		quote("2024-01-09", 1010, 0.5), // 1:2 の分割
+		quote("2024-01-04", 2000, 1),
+		quote("2024-01-05", 2020, 1),
+		quote("2024-01-10", 1000, 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := closes(s.Raw()); !approxSlice(got, []float64{2000, 2020, 1010, 1000}) {
+		t.Errorf("Unexpected raw closes: %v", got)
+	}
+	adjusted := s.Adjusted()
+	if got := closes(adjusted); !approxSlice(got, []float64{1000, 1010, 1010, 1000}) {
+		t.Errorf("Unexpected adjusted closes: %v", got)
+	}
+	if adjusted[0].Volume.Float64 != 2000 {
+		t.Errorf("Expected volume to be scaled inversely, got %v", adjusted[0].Volume)
+	}
+	actions := s.CorporateActions()
+	if len(actions) != 1 || actions[0].Date.String() != "2024-01-09" || !actions[0].IsSplit() {
+		t.Errorf("Unexpected corporate actions: %+v", actions)
+	}
+}
+
+func TestPriceSeriesReconstructsMissingFactors(t *testing.T) {
+	// AdjustmentFactor が無く (null や 0)、Adjustment* だけがある (取得時点で 1:2 分割済み) データ
+	q1 := quote("2024-01-05", 2000, 0)
+	q1.AdjustmentFactor = NullFloat64{}
+	q1.AdjustmentClose = Float(1000)
+	q2 := quote("2024-01-09", 1010, 0)
+	q2.AdjustmentClose = Float(1010)
+
+	s, err := NewPriceSeries([]DailyQuote{q1, q2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Factors(); !approxSlice(got, []float64{0.5, 1}) {
+		t.Errorf("Unexpected factors: %v", got)
+	}
+}
+
+func TestPriceSeriesIncrementalAppend(t *testing.T) {
+	s, err := NewPriceSeries([]DailyQuote{quote("2024-01-04", 2000, 1), quote("2024-01-05", 2020, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := s.Append([]DailyQuote{quote("2024-01-09", 2040, 1)}); err != nil || changed {
+		t.Errorf("Expected no re-adjustment, got changed=%v err=%v", changed, err)
+	}
+	// 新しい日に 1:4 の分割が来たら、それまでの日がすべて再調整される
+	if changed, err := s.Append([]DailyQuote{quote("2024-01-10", 505, 0.25)}); err != nil || !changed {
+		t.Errorf("Expected re-adjustment, got changed=%v err=%v", changed, err)
+	}
+	if got := closes(s.Adjusted()); !approxSlice(got, []float64{500, 505, 510, 505}) {
+		t.Errorf("Unexpected adjusted closes: %v", got)
+	}
+
+	// 過去分の訂正は全体を作り直す。終値だけの訂正では既存の日の係数は変わらない
+	if changed, err := s.Append([]DailyQuote{quote("2024-01-05", 2030, 1)}); err != nil || changed {
+		t.Errorf("Expected no re-adjustment, got changed=%v err=%v", changed, err)
+	}
+	if got := closes(s.Adjusted()); !approxSlice(got, []float64{500, 507.5, 510, 505}) {
+		t.Errorf("Unexpected adjusted closes after correction: %v", got)
+	}
+	// 過去の調整係数の訂正は、それより前の日の係数を変える
+	if changed, err := s.Append([]DailyQuote{quote("2024-01-09", 2040, 0.5)}); err != nil || !changed {
+		t.Errorf("Expected re-adjustment, got changed=%v err=%v", changed, err)
+	}
+	if got := closes(s.Adjusted()); !approxSlice(got, []float64{250, 253.75, 510, 505}) {
+		t.Errorf("Unexpected adjusted closes after factor correction: %v", got)
+	}
+
+	if _, err := s.Append([]DailyQuote{{Date: DateOf(day("2024-01-11")), Code: "13010"}}); err == nil {
+		t.Error("Expected error for a quote of another code")
+	}
+}
+
+func TestPriceSeriesDuplicateDates(t *testing.T) {
+	// 分割日が重複していても調整係数は一度だけ掛ける
+	s, err := NewPriceSeries([]DailyQuote{
+		quote("2024-01-05", 2000, 1),
+		quote("2024-01-09", 1000, 0.5),
+		quote("2024-01-09", 1010, 0.5),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := closes(s.Adjusted()); !approxSlice(got, []float64{1000, 1010}) {
+		t.Errorf("Unexpected adjusted closes: %v", got)
+	}
+
+	changed, err := s.Append([]DailyQuote{quote("2024-01-10", 505, 0.5), quote("2024-01-10", 500, 0.5)})
+	if err != nil || !changed {
+		t.Errorf("Expected re-adjustment, got changed=%v err=%v", changed, err)
+	}
+	if got := closes(s.Adjusted()); !approxSlice(got, []float64{500, 505, 500}) {
+		t.Errorf("Unexpected adjusted closes after append: %v", got)
+	}
+}
+
+func TestPriceSeriesDividendAdjusted(t *testing.T) {
+	halted := DailyQuote{Date: DateOf(day("2024-03-27")), Code: "72030", AdjustmentFactor: Float(1)}
+	s, err := NewPriceSeries([]DailyQuote{
+		quote("2024-03-26", 1000, 1),
+		halted,
+		quote("2024-03-28", 960, 1), // 権利落ち日
+		quote("2024-03-29", 970, 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetDividends([]CashDividend{
+		{CAReferenceNumber: "1", ExDate: "2024-03-28", GrossDividendRate: Float(50), ForecastResultCode: "1"},
+		{CAReferenceNumber: "2", ExDate: "2024-09-27", GrossDividendRate: Float(50), ForecastResultCode: "2"}, // 予想は使わない
+	})
+
+	bars := s.DividendAdjusted()
+	if got := closes(bars); !approxSlice(got, []float64{950, 0, 960, 970}) {
+		t.Errorf("Unexpected dividend-adjusted closes: %v", got)
+	}
+	if bars[1].Close.Valid {
+		t.Error("Expected a halted day to stay null")
+	}
+}
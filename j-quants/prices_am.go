@@ -0,0 +1,126 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// AMQuote は /prices/prices_am の1銘柄分の前場の四本値。当日の昼に公開される。
+// 前場に約定が無かった銘柄は四本値が null で返ってくるので、DailyQuote と同じく NullFloat64 で持つ
+type AMQuote struct {
+	Date                 Date        `json:"Date"`
+	Code                 string      `json:"Code"`
+	MorningOpen          NullFloat64 `json:"MorningOpen"`
+	MorningHigh          NullFloat64 `json:"MorningHigh"`
+	MorningLow           NullFloat64 `json:"MorningLow"`
+	MorningClose         NullFloat64 `json:"MorningClose"`
+	MorningVolume        NullFloat64 `json:"MorningVolume"`
+	MorningTurnoverValue NullFloat64 `json:"MorningTurnoverValue"`
+}
+
+// pricesAMResponse : JSON全体を受け取るための構造
+type pricesAMResponse struct {
+	PricesAM      []AMQuote `json:"prices_am"`
+	PaginationKey string    `json:"pagination_key"`
+}
+
+// GetPricesAMParams : クエリパラメータ。Code を省略すると全銘柄
+type GetPricesAMParams struct {
+	Code string
+}
+
+// GetPricesAM は /prices/prices_am を全ページ取得し、[]AMQuote を返す
+func (c *JQuantsClient) GetPricesAM(params GetPricesAMParams) ([]AMQuote, error) {
+	return c.GetPricesAMWithContext(context.Background(), params)
+}
+
+// GetPricesAMWithContext は GetPricesAM の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetPricesAMWithContext(ctx context.Context, params GetPricesAMParams) ([]AMQuote, error) {
+	return DoPaginatedGetWithContext[AMQuote](ctx, c, c.endpoint("/prices/prices_am"), params.values(), extractPricesAM)
+}
+
+// StreamPricesAM は /prices/prices_am を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamPricesAM(ctx context.Context, params GetPricesAMParams, handle PageHandler[AMQuote]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/prices/prices_am"), params.values(), extractPricesAM, handle)
+}
+
+func (params GetPricesAMParams) values() url.Values {
+	q := url.Values{}
+	if params.Code != "" {
+		q.Set("code", params.Code)
+	}
+	return q
+}
+
+func extractPricesAM(respBytes []byte) ([]AMQuote, string, error) {
+	var r pricesAMResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal prices_am: %w", err)
+	}
+	return r.PricesAM, r.PaginationKey, nil
+}
+
+// MorningSession は前場の値を前営業日の終値と突き合わせたもの。比率はすべて小数 (0.01 = 1%)
+type MorningSession struct {
+	AMQuote
+	PrevDate  Date
+	PrevClose float64
+
+	Gap          float64 // 前日終値に対する寄り付きのギャップ
+	Change       float64 // 前日終値に対する前場終値の騰落率
+	MorningRange float64 // 前場の値幅 (高値 - 安値) を前日終値で割ったもの
+	OpenToClose  float64 // 前場の寄り付きから前場終値までの騰落率
+}
+
+// MergeWithPreviousClose は前場の値それぞれに、同じ銘柄の直前の日足の終値を突き合わせる。
+// 直前の日足が無い銘柄や、前場に約定が無かった (四本値のどれかが null の) 銘柄は結果に含めない
+func MergeWithPreviousClose(am []AMQuote, daily []DailyQuote) []MorningSession {
+	byCode := make(map[string][]DailyQuote)
+	for _, q := range daily {
+		byCode[q.Code] = append(byCode[q.Code], q)
+	}
+	for _, quotes := range byCode {
+		sort.Slice(quotes, func(i, j int) bool {
+			return quotes[i].Date.Before(quotes[j].Date.Time)
+		})
+	}
+
+	var result []MorningSession
+	for _, q := range am {
+		if !q.MorningOpen.Valid || !q.MorningHigh.Valid || !q.MorningLow.Valid || !q.MorningClose.Valid {
+			continue
+		}
+		prev, ok := lastQuoteBefore(byCode[q.Code], q.Date)
+		if !ok {
+			continue
+		}
+		prevClose := prev.Close.Float64
+		result = append(result, MorningSession{
+			AMQuote:      q,
+			PrevDate:     prev.Date,
+			PrevClose:    prevClose,
+			Gap:          q.MorningOpen.Float64/prevClose - 1,
+			Change:       q.MorningClose.Float64/prevClose - 1,
+			MorningRange: (q.MorningHigh.Float64 - q.MorningLow.Float64) / prevClose,
+			OpenToClose:  q.MorningClose.Float64/q.MorningOpen.Float64 - 1,
+		})
+	}
+	return result
+}
+
+// lastQuoteBefore は日付順に並んだ quotes から date より前の最後の (終値のある) 日足を返す
+func lastQuoteBefore(quotes []DailyQuote, date Date) (DailyQuote, bool) {
+	i := sort.Search(len(quotes), func(i int) bool {
+		return !quotes[i].Date.Before(date.Time)
+	})
+	for i--; i >= 0; i-- {
+		if !quotes[i].Halted() {
+			return quotes[i], true
+		}
+	}
+	return DailyQuote{}, false
+}
@@ -0,0 +1,75 @@
+package jquants
+
+import (
+	"math"
+	"net/http"
+	"testing"
+)
+
+func TestGetPricesAM(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/prices/prices_am" || q.Get("code") != "7203" {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		switch q.Get("pagination_key") {
+		case "":
+			w.Write([]byte(`{"prices_am":[{"Date":"2024-01-05","Code":"72030","MorningOpen":2550.0,"MorningHigh":2600.0,"MorningLow":2500.0,"MorningClose":2575.0,"MorningVolume":1234500.0,"MorningTurnoverValue":3172000000.0}],"pagination_key":"next"}`))
+		case "next":
+			w.Write([]byte(`{"prices_am":[{"Date":"20240105","Code":"72030","MorningOpen":null,"MorningHigh":null,"MorningLow":null,"MorningClose":null,"MorningVolume":null,"MorningTurnoverValue":null}]}`))
+		default:
+			t.Errorf("Unexpected pagination key: %s", q.Get("pagination_key"))
+		}
+	}))
+
+	quotes, err := c.GetPricesAM(GetPricesAMParams{Code: "7203"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(quotes))
+	}
+	// 日付はどちらの形式でも同じ日になる
+	if !quotes[0].Date.Equal(day("2024-01-05")) || !quotes[1].Date.Equal(day("2024-01-05")) {
+		t.Errorf("Unexpected dates: %v, %v", quotes[0].Date, quotes[1].Date)
+	}
+	if quotes[0].MorningClose != Float(2575) || quotes[0].MorningVolume != Float(1234500) {
+		t.Errorf("Unexpected morning quote: %+v", quotes[0])
+	}
+	// 前場に約定が無ければ 0 ではなく値なしになる
+	if quotes[1].MorningOpen.Valid || quotes[1].MorningClose.Valid || quotes[1].MorningVolume.Valid {
+		t.Errorf("Expected null morning prices, got %+v", quotes[1])
+	}
+}
+
+func TestMergeWithPreviousClose(t *testing.T) {
+	am := []AMQuote{
+		{Date: DateOf(day("2024-01-05")), Code: "72030", MorningOpen: Float(2550), MorningHigh: Float(2600), MorningLow: Float(2500), MorningClose: Float(2575)},
+		{Date: DateOf(day("2024-01-05")), Code: "91040"}, // 前場に約定なし
+		{Date: DateOf(day("2024-01-05")), Code: "99990", MorningOpen: Float(100), MorningHigh: Float(100), MorningLow: Float(100), MorningClose: Float(100)},
+		{Date: DateOf(day("2024-01-05")), Code: "83060", MorningOpen: Float(1300), MorningClose: Float(1310)}, // 高値・安値が欠けている
+	}
+	daily := []DailyQuote{
+		{Date: DateOf(day("2024-01-05")), Code: "72030", Close: Float(9999)}, // 当日分は使わない
+		{Date: DateOf(day("2024-01-04")), Code: "72030", Close: Float(2500)},
+		{Date: DateOf(day("2023-12-28")), Code: "72030", Close: Float(2400)},
+		{Date: DateOf(day("2024-01-04")), Code: "91040", Close: Float(4000)},
+		{Date: DateOf(day("2024-01-04")), Code: "99990"}, // 売買停止で終値なし
+		{Date: DateOf(day("2024-01-04")), Code: "83060", Close: Float(1300)},
+	}
+
+	sessions := MergeWithPreviousClose(am, daily)
+	if len(sessions) != 1 {
+		t.Fatalf("Expected 1 session, got %+v", sessions)
+	}
+	s := sessions[0]
+	if s.PrevDate.String() != "2024-01-04" || s.PrevClose != 2500 {
+		t.Errorf("Unexpected previous quote: %s %v", s.PrevDate, s.PrevClose)
+	}
+	approx := func(got, want float64) bool { return math.Abs(got-want) < 1e-9 }
+	if !approx(s.Gap, 0.02) || !approx(s.Change, 0.03) || !approx(s.MorningRange, 0.04) || !approx(s.OpenToClose, 25.0/2550) {
+		t.Errorf("Unexpected signals: %+v", s)
+	}
+}
@@ -0,0 +1,167 @@
+package jquants
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Plan は J-Quants の契約プランを表す
+type Plan string
+
+const (
+	PlanFree     Plan = "free"
+	PlanLight    Plan = "light"
+	PlanStandard Plan = "standard"
+	PlanPremium  Plan = "premium"
+)
+
+// RateLimit は1分あたりのリクエスト数と、一度に連続して送れる上限 (バースト) を表す
+type RateLimit struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+// planRateLimits は各プランのリクエスト上限。上限ぎりぎりを避けるためバーストは小さめにしている
+var planRateLimits = map[Plan]RateLimit{
+	PlanFree:     {RequestsPerMinute: 5, Burst: 1},
+	PlanLight:    {RequestsPerMinute: 60, Burst: 5},
+	PlanStandard: {RequestsPerMinute: 120, Burst: 10},
+	PlanPremium:  {RequestsPerMinute: 500, Burst: 20},
+}
+
+// ParsePlan は "free" / "Light" のような文字列を Plan に変換する
+func ParsePlan(s string) (Plan, error) {
+	p := Plan(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := planRateLimits[p]; !ok {
+		return "", fmt.Errorf("unknown J-Quants plan: %q", s)
+	}
+	return p, nil
+}
+
+// RateLimit はプランごとのリクエスト上限を返す
+func (p Plan) RateLimit() RateLimit {
+	return planRateLimits[p]
+}
+
+// RateLimiterStats は RateLimiter の待機状況の集計
+type RateLimiterStats struct {
+	Requests  int64         // Wait が呼ばれた回数
+	Waited    int64         // そのうち待たされた回数
+	TotalWait time.Duration // 待った時間の合計
+	MaxWait   time.Duration // 1回あたりの最大待ち時間
+}
+
+// RateLimiter はトークンバケット方式のレートリミッタ。
+// 複数の goroutine やクライアントから共有して使える
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // トークン1つが補充されるまでの時間
+	burst    float64
+	tokens   float64
+	last     time.Time
+	stats    RateLimiterStats
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRateLimiter は指定の上限でリクエストを間引くレートリミッタを返す
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	burst := limit.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	rpm := limit.RequestsPerMinute
+	if rpm < 1 {
+		rpm = 1
+	}
+	return &RateLimiter{
+		interval: time.Minute / time.Duration(rpm),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+}
+
+// Wait はリクエストを1回送ってよくなるまで待つ。ctx がキャンセルされたらエラーを返す
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	// トークンを先に予約しておき、足りない分だけ待つ
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens * float64(l.interval))
+	}
+	l.stats.Requests++
+	if wait > 0 {
+		l.stats.Waited++
+		l.stats.TotalWait += wait
+		if wait > l.stats.MaxWait {
+			l.stats.MaxWait = wait
+		}
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	if err := l.sleep(ctx, wait); err != nil {
+		// 使わなかった予約分を返却する
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Stats はこれまでの待機状況を返す
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// WithPlan は契約プランに応じたレートリミッタをクライアントに設定する。
+// 大文字小文字の違いは許す。知らないプランであればログを残し、それまでのレートリミッタをそのまま使う
+func WithPlan(p Plan) Option {
+	return func(c *JQuantsClient) {
+		parsed, err := ParsePlan(string(p))
+		if err != nil {
+			log.Printf("[WARN] %v. Keeping the current rate limiter.", err)
+			return
+		}
+		c.limiter = NewRateLimiter(parsed.RateLimit())
+		c.plan = parsed
+	}
+}
+
+// WithRateLimiter は任意のレートリミッタを設定する。
+// 複数のクライアントで同じ上限を共有したい場合に使う。nil を渡すと制限しない
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(c *JQuantsClient) {
+		c.limiter = l
+	}
+}
+
+// RateLimiterStats はクライアントのレートリミッタの待機状況を返す。制限していない場合はゼロ値
+func (c *JQuantsClient) RateLimiterStats() RateLimiterStats {
+	if c.limiter == nil {
+		return RateLimiterStats{}
+	}
+	return c.limiter.Stats()
+}
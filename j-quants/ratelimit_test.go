@@ -0,0 +1,117 @@
+package jquants
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock は Sleep で待ち時間ぶん時刻を進めるだけのテスト用時計
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	return ctx.Err()
+}
+
+func newFakeRateLimiter(limit RateLimit) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC)}
+	l := NewRateLimiter(limit)
+	l.now = clock.Now
+	l.sleep = clock.Sleep
+	return l, clock
+}
+
+func TestRateLimiterPacesRequests(t *testing.T) {
+	l, clock := newFakeRateLimiter(RateLimit{RequestsPerMinute: 60, Burst: 2})
+	start := clock.Now()
+
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// バースト2件は即時、残り3件は1秒間隔
+	if elapsed := clock.Now().Sub(start); elapsed != 3*time.Second {
+		t.Errorf("Expected 3s elapsed, got %v", elapsed)
+	}
+	stats := l.Stats()
+	if stats.Requests != 5 || stats.Waited != 3 || stats.TotalWait != 3*time.Second || stats.MaxWait != time.Second {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestRateLimiterCancelled(t *testing.T) {
+	l, _ := newFakeRateLimiter(RateLimit{RequestsPerMinute: 1, Burst: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("Expected the burst token to be available, got: %v", err)
+	}
+	if err := l.Wait(ctx); err == nil {
+		t.Error("Expected error when waiting with a cancelled context")
+	}
+}
+
+func TestClientSharesRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	l, _ := newFakeRateLimiter(RateLimit{RequestsPerMinute: 60, Burst: 1})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"daily_quotes":[]}`))
+	})
+	c1 := newServerClient(t, handler, WithRateLimiter(l))
+	c2 := newServerClient(t, handler, WithRateLimiter(l))
+
+	for _, c := range []*JQuantsClient{c1, c2} {
+		if _, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := c1.RateLimiterStats(); stats.Requests != 2 || stats.Waited != 1 {
+		t.Errorf("Expected both clients to share the limiter, got: %+v", stats)
+	}
+}
+
+func TestParsePlan(t *testing.T) {
+	if p, err := ParsePlan(" Premium "); err != nil || p != PlanPremium {
+		t.Errorf("Expected PlanPremium, got %v (err=%v)", p, err)
+	}
+	if _, err := ParsePlan("gold"); err == nil {
+		t.Error("Expected error for unknown plan")
+	}
+}
+
+func TestWithPlanIgnoresUnknownPlan(t *testing.T) {
+	t.Parallel()
+
+	handler := http.NotFoundHandler()
+	c := newServerClient(t, handler, WithPlan(PlanLight))
+	light := c.limiter
+	// 知らないプランでは 1分1回に絞らず、それまでの設定を残す
+	WithPlan(Plan("gold"))(c)
+	if c.limiter != light || c.plan != PlanLight {
+		t.Errorf("Expected the light plan limiter to be kept, got plan=%q", c.plan)
+	}
+
+	// 大文字小文字が違うだけならそのプランとして扱う
+	c = newServerClient(t, handler, WithPlan(Plan("Standard")))
+	if c.plan != PlanStandard || c.limiter == nil || c.limiter.interval != time.Minute/120 {
+		t.Errorf("Expected the standard plan limiter, got plan=%q", c.plan)
+	}
+}
@@ -0,0 +1,309 @@
+package jquants
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachePolicy はレスポンスをどれだけの間キャッシュするかの設定
+type CachePolicy struct {
+	// HistoricalTTL は前日以前の日付だけを対象にしたリクエスト (date か to が過去) の保存期間。
+	// 過去のデータはほぼ変わらないので長くしてよい。
+	// ただし結果が空のページは公開前に問い合わせただけかもしれないので、EndpointTTLs / DefaultTTL で保存する
+	HistoricalTTL time.Duration
+	// EndpointTTLs は当日分を含みうるリクエストの、エンドポイント (例: "/prices/daily_quotes") ごとの保存期間
+	EndpointTTLs map[string]time.Duration
+	// DefaultTTL は EndpointTTLs に無いエンドポイントの保存期間。0 ならキャッシュしない
+	DefaultTTL time.Duration
+}
+
+// DefaultCachePolicy はデフォルトのキャッシュ設定
+var DefaultCachePolicy = CachePolicy{
+	HistoricalTTL: 30 * 24 * time.Hour,
+	EndpointTTLs: map[string]time.Duration{
+		"/prices/daily_quotes":      time.Hour,
+		"/prices/prices_am":         10 * time.Minute,
+		"/fins/statements":          30 * time.Minute,
+		"/fins/announcement":        30 * time.Minute,
+		"/listed/info":              12 * time.Hour,
+		"/markets/trading_calendar": 24 * time.Hour,
+	},
+	DefaultTTL: time.Hour,
+}
+
+// ttl は endpoint へのリクエストの保存期間を返す。empty はレスポンスに1件も含まれていなかったかどうか
+func (p CachePolicy) ttl(endpoint string, params url.Values, today time.Time, empty bool) time.Duration {
+	if latest, ok := latestRequestedDate(params); ok && latest.Before(today) && !empty {
+		return p.HistoricalTTL
+	}
+	if d, ok := p.EndpointTTLs[endpoint]; ok {
+		return d
+	}
+	return p.DefaultTTL
+}
+
+// latestRequestedDate はリクエストが対象とする最後の日付 (date または to) を返す。
+// 期間の終わりが決まっていない (最新分を含む) 場合は false
+func latestRequestedDate(params url.Values) (time.Time, bool) {
+	for _, key := range []string{"date", "to"} {
+		if v := params.Get(key); v != "" {
+			t, err := ParseDate(v)
+			return t, err == nil
+		}
+	}
+	return time.Time{}, false
+}
+
+// CacheStats はレスポンスキャッシュの利用状況
+type CacheStats struct {
+	Hits     int64 // キャッシュから返した回数
+	Misses   int64 // キャッシュに無く (または期限切れで) API に問い合わせた回数
+	Bypassed int64 // BypassCache で読み飛ばした回数
+	Stores   int64 // キャッシュに保存した回数
+}
+
+// ResponseCache は API のレスポンスをページ単位でディスクに保存するキャッシュ。
+// キーは接続先と契約プラン、エンドポイント、正規化したクエリパラメータ (pagination_key を含む) で、複数のクライアントから共有できる。
+// 無料プランは直近のデータが返らないなど、同じクエリでもプランで結果が変わるため、プランの違うクライアントの間では共有しない。
+// WithRateLimiter だけでプランを指定していないクライアントはプラン不明として別に扱う
+type ResponseCache struct {
+	dir    string
+	policy CachePolicy
+
+	mu    sync.Mutex
+	stats CacheStats
+
+	now func() time.Time
+}
+
+// cacheEntry はキャッシュファイルの中身
+type cacheEntry struct {
+	URL       string          `json:"url"`
+	StoredAt  time.Time       `json:"stored_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	Body      json.RawMessage `json:"body"`
+}
+
+// NewResponseCache は dir にレスポンスを保存するキャッシュを返す
+func NewResponseCache(dir string, policy CachePolicy) (*ResponseCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &ResponseCache{dir: dir, policy: policy, now: time.Now}, nil
+}
+
+// WithResponseCache はレスポンスキャッシュを設定する。nil を渡すとキャッシュしない
+func WithResponseCache(cache *ResponseCache) Option {
+	return func(c *JQuantsClient) {
+		c.cache = cache
+	}
+}
+
+type bypassCacheKey struct{}
+
+// BypassCache はキャッシュを読まずに API に問い合わせる context を返す。取得した結果でキャッシュは更新する
+func BypassCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+func isCacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return bypass
+}
+
+// Stats はこれまでの利用状況を返す
+func (rc *ResponseCache) Stats() CacheStats {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.stats
+}
+
+// cacheScope はレスポンスキャッシュのキーに含める接続先と契約プラン
+func (c *JQuantsClient) cacheScope() string {
+	return c.baseURL + " " + string(c.plan)
+}
+
+// CacheStats はクライアントのレスポンスキャッシュの利用状況を返す。キャッシュしていない場合はゼロ値
+func (c *JQuantsClient) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	return c.cache.Stats()
+}
+
+// Purge はすべてのキャッシュを消す
+func (rc *ResponseCache) Purge() error {
+	entries, err := os.ReadDir(rc.dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, e := range entries {
+		errs = append(errs, os.RemoveAll(filepath.Join(rc.dir, e.Name())))
+	}
+	return errors.Join(errs...)
+}
+
+// PurgeEndpoint は endpoint (例: "/fins/statements") のキャッシュだけを消す
+func (rc *ResponseCache) PurgeEndpoint(endpoint string) error {
+	return os.RemoveAll(rc.endpointDir(endpoint))
+}
+
+// PurgeExpired は期限切れのキャッシュを消し、消した件数を返す
+func (rc *ResponseCache) PurgeExpired() (int, error) {
+	now := rc.now()
+	removed := 0
+	err := filepath.WalkDir(rc.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		entry, err := readCacheEntry(path)
+		if err != nil || !now.Before(entry.ExpiresAt) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// endpointDir はエンドポイントごとのディレクトリ。PurgeEndpoint で丸ごと消せるよう分けている
+func (rc *ResponseCache) endpointDir(endpoint string) string {
+	name := strings.ReplaceAll(strings.Trim(endpoint, "/"), "/", "_")
+	if name == "" {
+		name = "_"
+	}
+	return filepath.Join(rc.dir, name)
+}
+
+// entryPath はリクエストに対応するキャッシュファイルの場所。scope は JQuantsClient.cacheScope の値
+func (rc *ResponseCache) entryPath(scope, endpoint string, params url.Values) string {
+	sum := sha256.Sum256([]byte(scope + "\n" + endpoint + "?" + normalizeCacheParams(endpoint, params).Encode()))
+	return filepath.Join(rc.endpointDir(endpoint), hex.EncodeToString(sum[:])+".json")
+}
+
+// stockCodeEndpoints は code に銘柄コードを取り、4桁と5桁のどちらで指定しても同じ結果を返すエンドポイント。
+// 指数や先物・オプションのコードは桁をそろえると別のコードになるので含めない
+var stockCodeEndpoints = map[string]bool{
+	"/prices/daily_quotes":             true,
+	"/prices/prices_am":                true,
+	"/listed/info":                     true,
+	"/fins/statements":                 true,
+	"/fins/fs_details":                 true,
+	"/fins/dividend":                   true,
+	"/markets/breakdown":               true,
+	"/markets/weekly_margin_interest":  true,
+	"/markets/daily_margin_interest":   true,
+	"/markets/short_selling_positions": true,
+}
+
+// normalizeCacheParams は同じ意味のクエリが同じキーになるよう、日付の表記を "2006-01-02" に、
+// 銘柄コードを取るエンドポイントでは4桁の銘柄コードを5桁に揃え、空の値を除く。
+// キーの順序は url.Values.Encode がそろえる
+func normalizeCacheParams(endpoint string, params url.Values) url.Values {
+	q := url.Values{}
+	for key, values := range params {
+		for _, v := range values {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			if key == "date" || key == "from" || key == "to" {
+				if t, err := ParseDate(v); err == nil {
+					v = t.Format(dateLayout)
+				}
+			}
+			if key == "code" && len(v) == 4 && stockCodeEndpoints[endpoint] {
+				v += "0"
+			}
+			q.Add(key, v)
+		}
+	}
+	return q
+}
+
+// get は有効なキャッシュがあればそのボディを返す
+func (rc *ResponseCache) get(ctx context.Context, scope, endpoint string, params url.Values) ([]byte, bool) {
+	if isCacheBypassed(ctx) {
+		rc.count(func(s *CacheStats) { s.Bypassed++ })
+		return nil, false
+	}
+	entry, err := readCacheEntry(rc.entryPath(scope, endpoint, params))
+	if err != nil || !rc.now().Before(entry.ExpiresAt) {
+		rc.count(func(s *CacheStats) { s.Misses++ })
+		return nil, false
+	}
+	rc.count(func(s *CacheStats) { s.Hits++ })
+	return entry.Body, true
+}
+
+// put はレスポンスを保存する。empty はページに1件も含まれていなかったかどうか。
+// 保存に失敗してもリクエスト自体は成功しているのでログだけ残す
+func (rc *ResponseCache) put(scope, endpoint string, params url.Values, fullURL string, body []byte, empty bool) {
+	now := rc.now()
+	ttl := rc.policy.ttl(endpoint, params, truncateDay(now), empty)
+	if ttl <= 0 || !json.Valid(body) {
+		return
+	}
+	b, err := json.Marshal(cacheEntry{URL: redactURL(fullURL), StoredAt: now, ExpiresAt: now.Add(ttl), Body: body})
+	if err == nil {
+		err = writeCacheFile(rc.entryPath(scope, endpoint, params), b)
+	}
+	if err != nil {
+		log.Printf("[WARN] Failed to write response cache: %v", err)
+		return
+	}
+	rc.count(func(s *CacheStats) { s.Stores++ })
+}
+
+func (rc *ResponseCache) count(update func(*CacheStats)) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	update(&rc.stats)
+}
+
+func readCacheEntry(path string) (*cacheEntry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &entry, nil
+}
+
+// writeCacheFile は一時ファイルに書いてから置き換える。同時に書かれても読み手が壊れたファイルを見ない
+func writeCacheFile(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
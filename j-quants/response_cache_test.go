@@ -0,0 +1,177 @@
+package jquants
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newCachedClient(t *testing.T) (*JQuantsClient, *ResponseCache, *atomic.Int32) {
+	t.Helper()
+	cache, err := NewResponseCache(t.TempDir(), DefaultCachePolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, JST)
+	cache.now = func() time.Time { return now }
+
+	var requests atomic.Int32
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"daily_quotes":[{"Date":"2024-01-04","Code":"72030","Close":2500}]}`))
+	}), WithResponseCache(cache))
+	return c, cache, &requests
+}
+
+func TestResponseCacheServesRepeatedQueries(t *testing.T) {
+	t.Parallel()
+	c, _, requests := newCachedClient(t)
+
+	for _, date := range []string{"2024-01-04", "20240104"} {
+		quotes, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203", Date: date})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(quotes) != 1 || quotes[0].Close != Float(2500) {
+			t.Errorf("Unexpected quotes: %+v", quotes)
+		}
+	}
+	// 日付の表記が違っても同じキーになる
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 request, got %d", requests.Load())
+	}
+	if stats := c.CacheStats(); stats.Hits != 1 || stats.Misses != 1 || stats.Stores != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// BypassCache は読まずに取得し直す
+	if _, err := c.GetDailyQuotesWithContext(BypassCache(context.Background()), GetDailyQuotesParams{Code: "7203", Date: "2024-01-04"}); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 2 || c.CacheStats().Bypassed != 1 {
+		t.Errorf("Expected the cache to be bypassed, got requests=%d stats=%+v", requests.Load(), c.CacheStats())
+	}
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	t.Parallel()
+	c, cache, requests := newCachedClient(t)
+	start := cache.now()
+
+	historical := GetDailyQuotesParams{Code: "7203", From: "2024-01-04", To: "2024-01-09"}
+	latest := GetDailyQuotesParams{Code: "7203"}
+	for _, p := range []GetDailyQuotesParams{historical, latest} {
+		if _, err := c.GetDailyQuotes(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 2時間後: 当日分を含むリクエストは期限切れ、過去分だけのリクエストはまだ有効
+	cache.now = func() time.Time { return start.Add(2 * time.Hour) }
+	for _, p := range []GetDailyQuotesParams{historical, latest} {
+		if _, err := c.GetDailyQuotes(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Expected only the latest query to be refetched, got %d requests", requests.Load())
+	}
+
+	if n, err := cache.PurgeExpired(); err != nil || n != 0 {
+		t.Errorf("Expected nothing to purge right after refetch, got %d (err=%v)", n, err)
+	}
+	if err := cache.PurgeEndpoint("/prices/daily_quotes"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetDailyQuotes(historical); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 4 {
+		t.Errorf("Expected a request after purge, got %d", requests.Load())
+	}
+}
+
+func TestResponseCacheSeparatesPlansAndHosts(t *testing.T) {
+	t.Parallel()
+	cache, err := NewResponseCache(t.TempDir(), DefaultCachePolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newPlanClient := func(requests *atomic.Int32, opts ...Option) *JQuantsClient {
+		return newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Write([]byte(`{"daily_quotes":[{"Date":"2024-01-04","Code":"72030","Close":2500}]}`))
+		}), append(opts, WithResponseCache(cache), WithRateLimiter(nil))...)
+	}
+	var freeRequests, premiumRequests, otherHostRequests atomic.Int32
+	free := newPlanClient(&freeRequests, WithPlan(PlanFree))
+	premium := newPlanClient(&premiumRequests, WithPlan(PlanPremium))
+	otherHost := newPlanClient(&otherHostRequests, WithPlan(PlanPremium))
+
+	params := GetDailyQuotesParams{Code: "7203", Date: "2024-01-04"}
+	for _, c := range []*JQuantsClient{free, premium, premium, otherHost} {
+		if _, err := c.GetDailyQuotes(params); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 同じプラン・同じ接続先のクライアントだけがキャッシュを共有する
+	if freeRequests.Load() != 1 || premiumRequests.Load() != 1 || otherHostRequests.Load() != 1 {
+		t.Errorf("Unexpected requests: free=%d premium=%d other host=%d", freeRequests.Load(), premiumRequests.Load(), otherHostRequests.Load())
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 3 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestNormalizeCacheParams(t *testing.T) {
+	a := url.Values{"code": {"7203"}, "from": {"20240104"}, "to": {""}}
+	b := url.Values{"from": {"2024-01-04"}, "code": {"72030"}}
+	if got, want := normalizeCacheParams("/prices/daily_quotes", a).Encode(), normalizeCacheParams("/prices/daily_quotes", b).Encode(); got != want {
+		t.Errorf("Expected equal keys: %s vs %s", got, want)
+	}
+
+	// 指数コードは桁をそろえない ("0028" と "00280" は別の指数になりうる)
+	index := url.Values{"code": {"0028"}}
+	if got := normalizeCacheParams("/indices", index).Get("code"); got != "0028" {
+		t.Errorf("Expected index code to be kept, got %q", got)
+	}
+}
+
+func TestResponseCacheEmptyHistoricalPage(t *testing.T) {
+	t.Parallel()
+	cache, err := NewResponseCache(t.TempDir(), DefaultCachePolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 10, 8, 0, 0, 0, JST)
+	cache.now = func() time.Time { return start }
+
+	// 前日分がまだ公開されておらず、空の結果が返ってくる
+	var requests atomic.Int32
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Write([]byte(`{"daily_quotes":[]}`))
+			return
+		}
+		w.Write([]byte(`{"daily_quotes":[{"Date":"2024-01-09","Code":"72030","Close":2500}]}`))
+	}), WithResponseCache(cache))
+
+	params := GetDailyQuotesParams{Date: "2024-01-09"}
+	if quotes, err := c.GetDailyQuotes(params); err != nil || len(quotes) != 0 {
+		t.Fatalf("Expected an empty result, got %+v (err=%v)", quotes, err)
+	}
+
+	// 空の結果は過去の日付でも長く保存せず、公開後に取り直す
+	cache.now = func() time.Time { return start.Add(2 * time.Hour) }
+	quotes, err := c.GetDailyQuotes(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 1 || requests.Load() != 2 {
+		t.Errorf("Expected the empty page to expire, got %+v (requests=%d)", quotes, requests.Load())
+	}
+}
@@ -0,0 +1,181 @@
+package jquants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy は一時的なエラー (429, 5xx, 通信エラー) に対するリトライ設定
+type RetryPolicy struct {
+	MaxAttempts    int           // 初回を含む最大試行回数。1 以下ならリトライしない
+	InitialBackoff time.Duration // 1回目のリトライまでの待ち時間
+	MaxBackoff     time.Duration // 待ち時間の上限 (Retry-After 指定時は除く)
+	Multiplier     float64       // リトライごとに待ち時間を何倍にするか
+	Jitter         float64       // 待ち時間に加えるランダム幅の割合 (0〜1)
+	MaxRetryAfter  time.Duration // Retry-After がこれより長ければ待たずにエラーを返す。0 なら上限なし
+}
+
+// DefaultRetryPolicy はクライアントのデフォルトのリトライ設定
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+	MaxRetryAfter:  5 * time.Minute,
+}
+
+// NoRetry はリトライを行わない設定
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// WithRetryPolicy はリトライ設定を差し替える
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *JQuantsClient) {
+		c.retryPolicy = p
+	}
+}
+
+// isRetryableStatus はリトライで回復が見込めるステータスかどうかを返す。
+// 400 や 403 などは何度送っても結果が変わらないのでリトライしない
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff は n 回目 (1始まり) のリトライ前に待つ時間を返す
+func (p RetryPolicy) backoff(n int) time.Duration {
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 1
+	}
+	d := float64(p.InitialBackoff) * math.Pow(mult, float64(n-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// parseRetryAfter は Retry-After ヘッダ (秒数 or HTTP日付) を待ち時間に変換する
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleepContext は d だけ待つ。途中で ctx がキャンセルされたらエラーを返す
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// do はリクエストを送り、200 であればレスポンスボディを返す。
+// 一時的なエラーは retryPolicy に従ってリトライする。リクエストはリトライごとに newReq で作り直す
+func (c *JQuantsClient) do(ctx context.Context, newReq func(ctx context.Context) (*http.Request, error)) ([]byte, error) {
+	maxAttempts := c.retryPolicy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		// リトライも含め、送信のたびにレートリミッタを通す
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		req, err := newReq(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		var wait time.Duration
+		var retryAfter bool
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			// キャンセルやタイムアウトはリトライしない
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			lastErr = fmt.Errorf("failed to do request: %w", redactURLError(err))
+		} else {
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if resp.StatusCode == http.StatusOK {
+				if readErr != nil {
+					return nil, fmt.Errorf("failed to read response: %w", readErr)
+				}
+				return body, nil
+			}
+
+			apiErr := newAPIError(c.endpointName(req.URL), req.URL.Query(), resp, body, c.clock.Now())
+			lastErr = apiErr
+			if !isRetryableStatus(resp.StatusCode) {
+				return nil, lastErr
+			}
+			wait, retryAfter = apiErr.RetryAfter, apiErr.RetryAfter > 0
+			if retryAfter && c.retryPolicy.MaxRetryAfter > 0 && wait > c.retryPolicy.MaxRetryAfter {
+				log.Printf("[WARN] %s %s asked to retry after %s, longer than %s. Giving up.", req.Method, req.URL.Path, wait, c.retryPolicy.MaxRetryAfter)
+				return nil, lastErr
+			}
+		}
+
+		if attempt >= maxAttempts {
+			if attempt > 1 {
+				return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, lastErr)
+			}
+			return nil, lastErr
+		}
+
+		if !retryAfter {
+			wait = c.retryPolicy.backoff(attempt)
+		}
+		log.Printf("[WARN] %s %s failed (attempt %d/%d): %v. Retrying in %s", req.Method, req.URL.Path, attempt, maxAttempts, lastErr, wait)
+		if err := c.sleep(ctx, wait); err != nil {
+			return nil, errors.Join(err, lastErr)
+		}
+	}
+}
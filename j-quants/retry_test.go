@@ -0,0 +1,134 @@
+package jquants
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordSleeps はクライアントの待機を記録だけして即座に戻すようにする
+func recordSleeps(c *JQuantsClient) *[]time.Duration {
+	var waits []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return &waits
+}
+
+func TestRetryOnTransientErrors(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`{"daily_quotes":[{"Date":"2024-01-04","Code":"72030"}]}`))
+		}
+	}), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, Multiplier: 2}))
+	waits := recordSleeps(c)
+
+	quotes, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"})
+	if err != nil {
+		t.Fatalf("Expected retries to succeed, got: %v", err)
+	}
+	if len(quotes) != 1 {
+		t.Errorf("Unexpected quotes: %+v", quotes)
+	}
+	// 1回目は指数バックオフ、2回目は Retry-After の値で待つ
+	want := []time.Duration{time.Second, 7 * time.Second}
+	if len(*waits) != len(want) || (*waits)[0] != want[0] || (*waits)[1] != want[1] {
+		t.Errorf("Expected waits %v, got %v", want, *waits)
+	}
+}
+
+func TestNoRetryOnPermanentErrors(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	recordSleeps(c)
+
+	if _, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"}); err == nil {
+		t.Fatal("Expected error for status 400")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 request for a permanent error, got %d", calls.Load())
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}))
+	recordSleeps(c)
+
+	if _, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"}); err == nil {
+		t.Fatal("Expected error after exhausting retries")
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 requests, got %d", calls.Load())
+	}
+}
+
+func TestRetryGivesUpOnLongRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, Multiplier: 2, MaxRetryAfter: time.Minute}))
+	waits := recordSleeps(c)
+
+	_, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != time.Hour {
+		t.Fatalf("Expected the rate limit error, got: %v", err)
+	}
+	// 1時間も待たずにすぐ返す
+	if calls.Load() != 1 || len(*waits) != 0 {
+		t.Errorf("Expected no retry, got %d requests and waits %v", calls.Load(), *waits)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC)
+
+	if d, ok := parseRetryAfter("30", now); !ok || d != 30*time.Second {
+		t.Errorf("Expected 30s, got %v (ok=%v)", d, ok)
+	}
+	date := now.Add(2 * time.Minute).Format(http.TimeFormat)
+	if d, ok := parseRetryAfter(date, now); !ok || d != 2*time.Minute {
+		t.Errorf("Expected 2m, got %v (ok=%v)", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		t.Error("Expected invalid Retry-After to be ignored")
+	}
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	if d := p.backoff(1); d != time.Second {
+		t.Errorf("Expected 1s, got %v", d)
+	}
+	if d := p.backoff(10); d != 5*time.Second {
+		t.Errorf("Expected backoff to be capped at 5s, got %v", d)
+	}
+}
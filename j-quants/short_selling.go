@@ -0,0 +1,186 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// ShortSelling は /markets/short_selling の1業種・1日分の空売り売買代金。値が無い場合は Valid = false
+type ShortSelling struct {
+	Date                                         string       `json:"Date"`
+	Sector33Code                                 Sector33Code `json:"Sector33Code"`
+	SellingExcludingShortSellingTurnoverValue    NullFloat64  `json:"SellingExcludingShortSellingTurnoverValue"`    // 実注文の売買代金
+	ShortSellingWithRestrictionsTurnoverValue    NullFloat64  `json:"ShortSellingWithRestrictionsTurnoverValue"`    // 価格規制ありの空売り
+	ShortSellingWithoutRestrictionsTurnoverValue NullFloat64  `json:"ShortSellingWithoutRestrictionsTurnoverValue"` // 価格規制なしの空売り
+}
+
+// ShortSellingRatio は売り全体に占める空売りの割合 (空売り比率) を返す。売りが無い、または値が欠けていれば false
+func (s ShortSelling) ShortSellingRatio() (float64, bool) {
+	if !s.SellingExcludingShortSellingTurnoverValue.Valid || !s.ShortSellingWithRestrictionsTurnoverValue.Valid || !s.ShortSellingWithoutRestrictionsTurnoverValue.Valid {
+		return 0, false
+	}
+	short := s.ShortSellingWithRestrictionsTurnoverValue.Float64 + s.ShortSellingWithoutRestrictionsTurnoverValue.Float64
+	total := s.SellingExcludingShortSellingTurnoverValue.Float64 + short
+	if total == 0 {
+		return 0, false
+	}
+	return short / total, true
+}
+
+// shortSellingResponse : JSON全体を受け取るための構造
+type shortSellingResponse struct {
+	ShortSelling  []ShortSelling `json:"short_selling"`
+	PaginationKey string         `json:"pagination_key"`
+}
+
+// GetShortSellingParams : クエリパラメータ。Sector33Code か Date のどちらかは必須
+type GetShortSellingParams struct {
+	Sector33Code Sector33Code
+	Date         string
+	From         string
+	To           string
+}
+
+// GetShortSelling は /markets/short_selling を全ページ取得し、[]ShortSelling を返す
+func (c *JQuantsClient) GetShortSelling(params GetShortSellingParams) ([]ShortSelling, error) {
+	return c.GetShortSellingWithContext(context.Background(), params)
+}
+
+// GetShortSellingWithContext は GetShortSelling の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetShortSellingWithContext(ctx context.Context, params GetShortSellingParams) ([]ShortSelling, error) {
+	return DoPaginatedGetWithContext[ShortSelling](ctx, c, c.endpoint("/markets/short_selling"), params.values(), extractShortSelling)
+}
+
+// StreamShortSelling は /markets/short_selling を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamShortSelling(ctx context.Context, params GetShortSellingParams, handle PageHandler[ShortSelling]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/markets/short_selling"), params.values(), extractShortSelling, handle)
+}
+
+func (params GetShortSellingParams) values() url.Values {
+	q := url.Values{}
+	if params.Sector33Code != "" {
+		q.Set("sector33code", string(params.Sector33Code))
+	}
+	if params.Date != "" {
+		q.Set("date", params.Date)
+	}
+	if params.From != "" {
+		q.Set("from", params.From)
+	}
+	if params.To != "" {
+		q.Set("to", params.To)
+	}
+	return q
+}
+
+func extractShortSelling(respBytes []byte) ([]ShortSelling, string, error) {
+	var r shortSellingResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal short_selling: %w", err)
+	}
+	return r.ShortSelling, r.PaginationKey, nil
+}
+
+// ShortSellingPosition は /markets/short_selling_positions の空売り残高報告1件分。値が無い場合は Valid = false
+type ShortSellingPosition struct {
+	DisclosedDate                            string      `json:"DisclosedDate"`
+	CalculatedDate                           string      `json:"CalculatedDate"`
+	Code                                     string      `json:"Code"`
+	ShortSellerName                          string      `json:"ShortSellerName"`
+	ShortSellerAddress                       string      `json:"ShortSellerAddress"`
+	DiscretionaryInvestmentContractorName    string      `json:"DiscretionaryInvestmentContractorName"`
+	DiscretionaryInvestmentContractorAddress string      `json:"DiscretionaryInvestmentContractorAddress"`
+	InvestmentFundName                       string      `json:"InvestmentFundName"`
+	ShortPositionsToSharesOutstandingRatio   NullFloat64 `json:"ShortPositionsToSharesOutstandingRatio"`
+	ShortPositionsInSharesNumber             NullFloat64 `json:"ShortPositionsInSharesNumber"`
+	ShortPositionsInTradingUnitsNumber       NullFloat64 `json:"ShortPositionsInTradingUnitsNumber"`
+	CalculationInPreviousReportingDate       string      `json:"CalculationInPreviousReportingDate"`
+	ShortPositionsInPreviousReportingRatio   NullFloat64 `json:"ShortPositionsInPreviousReportingRatio"`
+	Notes                                    string      `json:"Notes"`
+}
+
+// shortSellingPositionsResponse : JSON全体を受け取るための構造
+type shortSellingPositionsResponse struct {
+	ShortSellingPositions []ShortSellingPosition `json:"short_selling_positions"`
+	PaginationKey         string                 `json:"pagination_key"`
+}
+
+// GetShortSellingPositionsParams : クエリパラメータ。Code, DisclosedDate, CalculatedDate のいずれかは必須
+type GetShortSellingPositionsParams struct {
+	Code              string
+	DisclosedDate     string
+	DisclosedDateFrom string
+	DisclosedDateTo   string
+	CalculatedDate    string
+}
+
+// GetShortSellingPositions は /markets/short_selling_positions を全ページ取得し、[]ShortSellingPosition を返す
+func (c *JQuantsClient) GetShortSellingPositions(params GetShortSellingPositionsParams) ([]ShortSellingPosition, error) {
+	return c.GetShortSellingPositionsWithContext(context.Background(), params)
+}
+
+// GetShortSellingPositionsWithContext は GetShortSellingPositions の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetShortSellingPositionsWithContext(ctx context.Context, params GetShortSellingPositionsParams) ([]ShortSellingPosition, error) {
+	return DoPaginatedGetWithContext[ShortSellingPosition](ctx, c, c.endpoint("/markets/short_selling_positions"), params.values(), extractShortSellingPositions)
+}
+
+// StreamShortSellingPositions は /markets/short_selling_positions を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamShortSellingPositions(ctx context.Context, params GetShortSellingPositionsParams, handle PageHandler[ShortSellingPosition]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/markets/short_selling_positions"), params.values(), extractShortSellingPositions, handle)
+}
+
+func (params GetShortSellingPositionsParams) values() url.Values {
+	q := url.Values{}
+	if params.Code != "" {
+		q.Set("code", params.Code)
+	}
+	if params.DisclosedDate != "" {
+		q.Set("disclosed_date", params.DisclosedDate)
+	}
+	if params.DisclosedDateFrom != "" {
+		q.Set("disclosed_date_from", params.DisclosedDateFrom)
+	}
+	if params.DisclosedDateTo != "" {
+		q.Set("disclosed_date_to", params.DisclosedDateTo)
+	}
+	if params.CalculatedDate != "" {
+		q.Set("calculated_date", params.CalculatedDate)
+	}
+	return q
+}
+
+func extractShortSellingPositions(respBytes []byte) ([]ShortSellingPosition, string, error) {
+	var r shortSellingPositionsResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal short_selling_positions: %w", err)
+	}
+	return r.ShortSellingPositions, r.PaginationKey, nil
+}
+
+// ShortInterestByCode は銘柄ごとに、報告者 (空売り者・投資一任契約・ファンドの組み合わせ) ごとの
+// 最新の残高割合を合計した値を返す。0.5% 以上の報告義務分だけの合計なので、実際の空売り残高の下限の目安になる。
+// 残高割合が値なしの報告は合計に含めない
+func ShortInterestByCode(positions []ShortSellingPosition) map[string]float64 {
+	type reporter struct {
+		code, seller, contractor, fund string
+	}
+	latest := make(map[reporter]ShortSellingPosition)
+	for _, p := range positions {
+		key := reporter{p.Code, p.ShortSellerName, p.DiscretionaryInvestmentContractorName, p.InvestmentFundName}
+		if prev, ok := latest[key]; !ok || p.CalculatedDate > prev.CalculatedDate {
+			latest[key] = p
+		}
+	}
+
+	result := make(map[string]float64)
+	for key, p := range latest {
+		if p.ShortPositionsToSharesOutstandingRatio.Valid {
+			result[key.code] += p.ShortPositionsToSharesOutstandingRatio.Float64
+		}
+	}
+	return result
+}
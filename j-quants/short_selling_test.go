@@ -0,0 +1,108 @@
+package jquants
+
+import (
+	"math"
+	"net/http"
+	"testing"
+)
+
+func TestGetShortSelling(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/markets/short_selling" || q.Get("sector33code") != "0050" || q.Get("from") != "2024-01-04" || q.Get("to") != "2024-01-05" {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		switch q.Get("pagination_key") {
+		case "":
+			w.Write([]byte(`{"short_selling":[{"Date":"2024-01-04","Sector33Code":"0050","SellingExcludingShortSellingTurnoverValue":6000000.0,"ShortSellingWithRestrictionsTurnoverValue":3000000.0,"ShortSellingWithoutRestrictionsTurnoverValue":1000000.0}],"pagination_key":"next"}`))
+		case "next":
+			w.Write([]byte(`{"short_selling":[{"Date":"2024-01-05","Sector33Code":"0050","SellingExcludingShortSellingTurnoverValue":"-","ShortSellingWithRestrictionsTurnoverValue":null,"ShortSellingWithoutRestrictionsTurnoverValue":0.0}]}`))
+		default:
+			t.Errorf("Unexpected pagination key: %s", q.Get("pagination_key"))
+		}
+	}))
+
+	rows, err := c.GetShortSelling(GetShortSellingParams{Sector33Code: Sector33Fishery, From: "2024-01-04", To: "2024-01-05"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(rows))
+	}
+	if ratio, ok := rows[0].ShortSellingRatio(); !ok || math.Abs(ratio-0.4) > 1e-9 {
+		t.Errorf("Expected 0.4, got %v (ok=%v)", ratio, ok)
+	}
+	// "-" や null は 0 ではなく値なしになる
+	if rows[1].SellingExcludingShortSellingTurnoverValue.Valid || rows[1].ShortSellingWithRestrictionsTurnoverValue.Valid || rows[1].ShortSellingWithoutRestrictionsTurnoverValue != Float(0) {
+		t.Errorf("Unexpected values: %+v", rows[1])
+	}
+	if _, ok := rows[1].ShortSellingRatio(); ok {
+		t.Error("Expected no ratio with missing values")
+	}
+}
+
+func TestGetShortSellingPositions(t *testing.T) {
+	t.Parallel()
+
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/markets/short_selling_positions" || q.Get("code") != "7203" ||
+			q.Get("disclosed_date_from") != "2024-01-04" || q.Get("disclosed_date_to") != "2024-01-10" {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+		switch q.Get("pagination_key") {
+		case "":
+			w.Write([]byte(`{"short_selling_positions":[{"DisclosedDate":"2024-01-05","CalculatedDate":"2024-01-04","Code":"72030","ShortSellerName":"A","ShortPositionsToSharesOutstandingRatio":0.006,"ShortPositionsInSharesNumber":9000000,"ShortPositionsInTradingUnitsNumber":90000,"CalculationInPreviousReportingDate":"","ShortPositionsInPreviousReportingRatio":""}],"pagination_key":"next"}`))
+		case "next":
+			w.Write([]byte(`{"short_selling_positions":[{"DisclosedDate":"2024-01-10","CalculatedDate":"2024-01-09","Code":"72030","ShortSellerName":"A","ShortPositionsToSharesOutstandingRatio":0.008,"ShortPositionsInSharesNumber":12000000,"ShortPositionsInTradingUnitsNumber":120000,"CalculationInPreviousReportingDate":"2024-01-04","ShortPositionsInPreviousReportingRatio":0.006}]}`))
+		default:
+			t.Errorf("Unexpected pagination key: %s", q.Get("pagination_key"))
+		}
+	}))
+
+	positions, err := c.GetShortSellingPositions(GetShortSellingPositionsParams{Code: "7203", DisclosedDateFrom: "2024-01-04", DisclosedDateTo: "2024-01-10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(positions))
+	}
+	// 前回報告の無い初回の報告は、前回の割合が値なしになる
+	if positions[0].ShortPositionsInPreviousReportingRatio.Valid || positions[1].ShortPositionsInPreviousReportingRatio != Float(0.006) {
+		t.Errorf("Unexpected previous ratios: %+v", positions)
+	}
+	if got := ShortInterestByCode(positions); math.Abs(got["72030"]-0.008) > 1e-9 {
+		t.Errorf("Unexpected short interest: %v", got)
+	}
+}
+
+func TestShortInterestByCode(t *testing.T) {
+	positions := []ShortSellingPosition{
+		{Code: "72030", CalculatedDate: "2024-01-04", ShortSellerName: "A", ShortPositionsToSharesOutstandingRatio: Float(0.006)},
+		{Code: "72030", CalculatedDate: "2024-01-10", ShortSellerName: "A", ShortPositionsToSharesOutstandingRatio: Float(0.008)},
+		{Code: "72030", CalculatedDate: "2024-01-05", ShortSellerName: "B", ShortPositionsToSharesOutstandingRatio: Float(0.005)},
+		{Code: "91040", CalculatedDate: "2024-01-05", ShortSellerName: "A", ShortPositionsToSharesOutstandingRatio: Float(0.01)},
+	}
+
+	got := ShortInterestByCode(positions)
+	// 72030 は A の最新 (0.8%) と B (0.5%) の合計
+	if math.Abs(got["72030"]-0.013) > 1e-9 || math.Abs(got["91040"]-0.01) > 1e-9 {
+		t.Errorf("Unexpected short interest: %v", got)
+	}
+}
+
+func TestShortSellingRatio(t *testing.T) {
+	s := ShortSelling{
+		SellingExcludingShortSellingTurnoverValue:    Float(600),
+		ShortSellingWithRestrictionsTurnoverValue:    Float(300),
+		ShortSellingWithoutRestrictionsTurnoverValue: Float(100),
+	}
+	if ratio, ok := s.ShortSellingRatio(); !ok || math.Abs(ratio-0.4) > 1e-9 {
+		t.Errorf("Expected 0.4, got %v (ok=%v)", ratio, ok)
+	}
+	if _, ok := (ShortSelling{}).ShortSellingRatio(); ok {
+		t.Error("Expected no ratio without any selling")
+	}
+}
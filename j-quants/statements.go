@@ -1,6 +1,7 @@
 package jquants
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -131,7 +132,21 @@ type GetStatementsParams struct {
 
 // GetStatements は /fins/statements をページネーション対応で全件取得し、[]Statement を返す
 func (c *JQuantsClient) GetStatements(params GetStatementsParams) ([]Statement, error) {
-	baseURL := "https://api.jquants.com/v1/fins/statements"
+	return c.GetStatementsWithContext(context.Background(), params)
+}
+
+// GetStatementsWithContext は GetStatements の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetStatementsWithContext(ctx context.Context, params GetStatementsParams) ([]Statement, error) {
+	return DoPaginatedGetWithContext[Statement](ctx, c, c.endpoint("/fins/statements"), params.values(), extractStatements)
+}
+
+// StreamStatements は /fins/statements を1ページずつ handle に渡す。全件をメモリに溜めない
+func (c *JQuantsClient) StreamStatements(ctx context.Context, params GetStatementsParams, handle PageHandler[Statement]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/fins/statements"), params.values(), extractStatements, handle)
+}
+
+func (params GetStatementsParams) values() url.Values {
 	q := url.Values{}
 	if params.Code != "" {
 		q.Set("code", params.Code)
@@ -139,16 +154,14 @@ func (c *JQuantsClient) GetStatements(params GetStatementsParams) ([]Statement,
 	if params.Date != "" {
 		q.Set("date", params.Date)
 	}
+	return q
+}
 
-	// extract 関数で JSON のレスポンスから []Statement と pagination_key を抽出
-	extractor := func(respBytes []byte) ([]Statement, string, error) {
-		var r statementsResponse
-		if err := json.Unmarshal(respBytes, &r); err != nil {
-			return nil, "", fmt.Errorf("failed to unmarshal statements: %w", err)
-		}
-		return r.Statements, r.PaginationKey, nil
+// extractStatements は JSON のレスポンスから []Statement と pagination_key を抽出する
+func extractStatements(respBytes []byte) ([]Statement, string, error) {
+	var r statementsResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal statements: %w", err)
 	}
-
-	return DoPaginatedGet[Statement](c, baseURL, q, extractor)
+	return r.Statements, r.PaginationKey, nil
 }
-
@@ -0,0 +1,235 @@
+package jquants
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DocumentType は開示書類の種別 (Statement.TypeOfDocument)。
+// 決算短信は "FYFinancialStatements_Consolidated_JP" のように期間・連結区分・会計基準を "_" でつないだ値になる
+type DocumentType string
+
+const (
+	DocumentEarnForecastRevision         DocumentType = "EarnForecastRevision"
+	DocumentDividendForecastRevision     DocumentType = "DividendForecastRevision"
+	DocumentREITEarnForecastRevision     DocumentType = "REITEarnForecastRevision"
+	DocumentREITDividendForecastRevision DocumentType = "REITDividendForecastRevision"
+)
+
+// IsFinancialStatements は決算短信 (実績を含む書類) かどうかを返す
+func (d DocumentType) IsFinancialStatements() bool {
+	return strings.Contains(string(d), "FinancialStatements")
+}
+
+// IsForecastRevision は業績予想・配当予想の修正かどうかを返す
+func (d DocumentType) IsForecastRevision() bool {
+	return strings.HasSuffix(string(d), "ForecastRevision")
+}
+
+// IsConsolidated は連結の決算短信かどうかを返す
+func (d DocumentType) IsConsolidated() bool {
+	return strings.Contains(string(d), "_Consolidated")
+}
+
+// AccountingStandard は決算短信の会計基準を返す。読み取れなければ空文字
+func (d DocumentType) AccountingStandard() AccountingStandard {
+	return accountingStandardOf(string(d))
+}
+
+// PeriodType は会計期間の種別 (Statement.TypeOfCurrentPeriod)
+type PeriodType string
+
+const (
+	Period1Q PeriodType = "1Q"
+	Period2Q PeriodType = "2Q"
+	Period3Q PeriodType = "3Q"
+	// 4Q, 5Q は決算期の変更で会計期間が1年を超える場合に使われる
+	Period4Q PeriodType = "4Q"
+	Period5Q PeriodType = "5Q"
+	PeriodFY PeriodType = "FY"
+)
+
+// ParsedStatement は Statement の各値を型付きで持つ版。フィールド名は Statement と同じ。
+// 金額と株数は NullInt64、1株あたりの値と比率は NullFloat64、日付は NullDate、変更の有無は NullBool で、
+// 空文字と "-" はどれも値なし (Valid = false) になる
+type ParsedStatement struct {
+	// 開示情報
+	DisclosedDate              NullDate
+	DisclosedTime              string
+	LocalCode                  string
+	DisclosureNumber           string
+	TypeOfDocument             DocumentType
+	TypeOfCurrentPeriod        PeriodType
+	CurrentPeriodStartDate     NullDate
+	CurrentPeriodEndDate       NullDate
+	CurrentFiscalYearStartDate NullDate
+	CurrentFiscalYearEndDate   NullDate
+	NextFiscalYearStartDate    NullDate
+	NextFiscalYearEndDate      NullDate
+
+	// 連結の実績 (金額は円)
+	NetSales                         NullInt64
+	OperatingProfit                  NullInt64
+	OrdinaryProfit                   NullInt64
+	Profit                           NullInt64
+	EarningsPerShare                 NullFloat64
+	DilutedEarningsPerShare          NullFloat64
+	TotalAssets                      NullInt64
+	Equity                           NullInt64
+	EquityToAssetRatio               NullFloat64
+	BookValuePerShare                NullFloat64
+	CashFlowsFromOperatingActivities NullInt64
+	CashFlowsFromInvestingActivities NullInt64
+	CashFlowsFromFinancingActivities NullInt64
+	CashAndEquivalents               NullInt64
+
+	// 配当 (1株あたりは円、配当性向は小数の比率)
+	ResultDividendPerShare1stQuarter              NullFloat64
+	ResultDividendPerShare2ndQuarter              NullFloat64
+	ResultDividendPerShare3rdQuarter              NullFloat64
+	ResultDividendPerShareFiscalYearEnd           NullFloat64
+	ResultDividendPerShareAnnual                  NullFloat64
+	DistributionsPerUnit                          NullFloat64
+	ResultTotalDividendPaidAnnual                 NullInt64
+	ResultPayoutRatioAnnual                       NullFloat64
+	ForecastDividendPerShare1stQuarter            NullFloat64
+	ForecastDividendPerShare2ndQuarter            NullFloat64
+	ForecastDividendPerShare3rdQuarter            NullFloat64
+	ForecastDividendPerShareFiscalYearEnd         NullFloat64
+	ForecastDividendPerShareAnnual                NullFloat64
+	ForecastDistributionsPerUnit                  NullFloat64
+	ForecastTotalDividendPaidAnnual               NullInt64
+	ForecastPayoutRatioAnnual                     NullFloat64
+	NextYearForecastDividendPerShare1stQuarter    NullFloat64
+	NextYearForecastDividendPerShare2ndQuarter    NullFloat64
+	NextYearForecastDividendPerShare3rdQuarter    NullFloat64
+	NextYearForecastDividendPerShareFiscalYearEnd NullFloat64
+	NextYearForecastDividendPerShareAnnual        NullFloat64
+	NextYearForecastDistributionsPerUnit          NullFloat64
+	NextYearForecastPayoutRatioAnnual             NullFloat64
+
+	// 連結の業績予想
+	ForecastNetSales2ndQuarter                 NullInt64
+	ForecastOperatingProfit2ndQuarter          NullInt64
+	ForecastOrdinaryProfit2ndQuarter           NullInt64
+	ForecastProfit2ndQuarter                   NullInt64
+	ForecastEarningsPerShare2ndQuarter         NullFloat64
+	NextYearForecastNetSales2ndQuarter         NullInt64
+	NextYearForecastOperatingProfit2ndQuarter  NullInt64
+	NextYearForecastOrdinaryProfit2ndQuarter   NullInt64
+	NextYearForecastProfit2ndQuarter           NullInt64
+	NextYearForecastEarningsPerShare2ndQuarter NullFloat64
+	ForecastNetSales                           NullInt64
+	ForecastOperatingProfit                    NullInt64
+	ForecastOrdinaryProfit                     NullInt64
+	ForecastProfit                             NullInt64
+	ForecastEarningsPerShare                   NullFloat64
+	NextYearForecastNetSales                   NullInt64
+	NextYearForecastOperatingProfit            NullInt64
+	NextYearForecastOrdinaryProfit             NullInt64
+	NextYearForecastProfit                     NullInt64
+	NextYearForecastEarningsPerShare           NullFloat64
+
+	// 会計方針の変更などのフラグ
+	MaterialChangesInSubsidiaries                            NullBool
+	SignificantChangesInTheScopeOfConsolidation              NullBool
+	ChangesBasedOnRevisionsOfAccountingStandard              NullBool
+	ChangesOtherThanOnesBasedOnRevisionsOfAccountingStandard NullBool
+	ChangesInAccountingEstimates                             NullBool
+	RetrospectiveRestatement                                 NullBool
+
+	// 株数
+	NumberOfIssuedAndOutstandingSharesAtTheEndOfFiscalYearIncludingTreasuryStock NullInt64
+	NumberOfTreasuryStockAtTheEndOfFiscalYear                                    NullInt64
+	AverageNumberOfShares                                                        NullInt64
+
+	// 単体の実績と業績予想
+	NonConsolidatedNetSales                                   NullInt64
+	NonConsolidatedOperatingProfit                            NullInt64
+	NonConsolidatedOrdinaryProfit                             NullInt64
+	NonConsolidatedProfit                                     NullInt64
+	NonConsolidatedEarningsPerShare                           NullFloat64
+	NonConsolidatedTotalAssets                                NullInt64
+	NonConsolidatedEquity                                     NullInt64
+	NonConsolidatedEquityToAssetRatio                         NullFloat64
+	NonConsolidatedBookValuePerShare                          NullFloat64
+	ForecastNonConsolidatedNetSales2ndQuarter                 NullInt64
+	ForecastNonConsolidatedOperatingProfit2ndQuarter          NullInt64
+	ForecastNonConsolidatedOrdinaryProfit2ndQuarter           NullInt64
+	ForecastNonConsolidatedProfit2ndQuarter                   NullInt64
+	ForecastNonConsolidatedEarningsPerShare2ndQuarter         NullFloat64
+	NextYearForecastNonConsolidatedNetSales2ndQuarter         NullInt64
+	NextYearForecastNonConsolidatedOperatingProfit2ndQuarter  NullInt64
+	NextYearForecastNonConsolidatedOrdinaryProfit2ndQuarter   NullInt64
+	NextYearForecastNonConsolidatedProfit2ndQuarter           NullInt64
+	NextYearForecastNonConsolidatedEarningsPerShare2ndQuarter NullFloat64
+	ForecastNonConsolidatedNetSales                           NullInt64
+	ForecastNonConsolidatedOperatingProfit                    NullInt64
+	ForecastNonConsolidatedOrdinaryProfit                     NullInt64
+	ForecastNonConsolidatedProfit                             NullInt64
+	ForecastNonConsolidatedEarningsPerShare                   NullFloat64
+	NextYearForecastNonConsolidatedNetSales                   NullInt64
+	NextYearForecastNonConsolidatedOperatingProfit            NullInt64
+	NextYearForecastNonConsolidatedOrdinaryProfit             NullInt64
+	NextYearForecastNonConsolidatedProfit                     NullInt64
+	NextYearForecastNonConsolidatedEarningsPerShare           NullFloat64
+}
+
+// Parse は Statement を ParsedStatement に変換する。
+// 読めない値があった場合は、そのフィールドを値なしにしたうえで、フィールド名を含むエラーをまとめて返す
+func (st Statement) Parse() (ParsedStatement, error) {
+	var parsed ParsedStatement
+	src := reflect.ValueOf(st)
+	dst := reflect.ValueOf(&parsed).Elem()
+
+	var errs []error
+	for i := 0; i < dst.NumField(); i++ {
+		name := dst.Type().Field(i).Name
+		raw := src.FieldByName(name).String()
+		if err := parseStatementField(dst.Field(i), raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return parsed, errors.Join(errs...)
+}
+
+// parseStatementField は raw を dst の型に合わせて読み、dst に設定する
+func parseStatementField(dst reflect.Value, raw string) error {
+	var v any
+	var err error
+	switch dst.Interface().(type) {
+	case NullInt64:
+		v, err = parseNullInt64(raw)
+	case NullFloat64:
+		v, err = parseNullFloat64(raw)
+	case NullBool:
+		v, err = parseNullBool(raw)
+	case NullDate:
+		v, err = parseNullDate(raw)
+	default:
+		// string とその派生型 (DocumentType, PeriodType) はそのまま持つ
+		dst.SetString(raw)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	dst.Set(reflect.ValueOf(v))
+	return nil
+}
+
+// ParseStatements は複数の Statement をまとめて変換する。エラーがあっても全件を変換し、エラーはまとめて返す
+func ParseStatements(statements []Statement) ([]ParsedStatement, error) {
+	result := make([]ParsedStatement, len(statements))
+	var errs []error
+	for i, st := range statements {
+		parsed, err := st.Parse()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("statement %s (%s): %w", st.DisclosureNumber, st.LocalCode, err))
+		}
+		result[i] = parsed
+	}
+	return result, errors.Join(errs...)
+}
@@ -0,0 +1,91 @@
+package jquants
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsedStatementCoversAllFields(t *testing.T) {
+	raw := reflect.TypeOf(Statement{})
+	parsed := reflect.TypeOf(ParsedStatement{})
+	if raw.NumField() != parsed.NumField() {
+		t.Fatalf("Statement has %d fields, ParsedStatement has %d", raw.NumField(), parsed.NumField())
+	}
+	for i := 0; i < raw.NumField(); i++ {
+		if _, ok := parsed.FieldByName(raw.Field(i).Name); !ok {
+			t.Errorf("ParsedStatement is missing %s", raw.Field(i).Name)
+		}
+	}
+}
+
+func TestStatementParse(t *testing.T) {
+	var st Statement
+	err := json.Unmarshal([]byte(`{
+		"DisclosedDate":"2024-05-08","DisclosedTime":"13:55:00","LocalCode":"72030",
+		"TypeOfDocument":"FYFinancialStatements_Consolidated_IFRS","TypeOfCurrentPeriod":"FY",
+		"CurrentPeriodEndDate":"2024-03-31","NextFiscalYearStartDate":"",
+		"NetSales":"45095325000000","OperatingProfit":"-","EarningsPerShare":"365.94",
+		"EquityToAssetRatio":"0.381","MaterialChangesInSubsidiaries":"false","RetrospectiveRestatement":"true",
+		"AverageNumberOfShares":"13476292462"
+	}`), &st)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := st.Parse()
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !p.DisclosedDate.Valid || !p.DisclosedDate.Time.Equal(time.Date(2024, 5, 8, 0, 0, 0, 0, JST)) {
+		t.Errorf("Unexpected DisclosedDate: %v", p.DisclosedDate)
+	}
+	if p.NextFiscalYearStartDate.Valid || p.OperatingProfit.Valid || p.Profit.Valid {
+		t.Error("Expected empty and \"-\" values to be invalid")
+	}
+	if p.NetSales != Int(45095325000000) || p.AverageNumberOfShares != Int(13476292462) {
+		t.Errorf("Unexpected integers: %v %v", p.NetSales, p.AverageNumberOfShares)
+	}
+	if p.EarningsPerShare != Float(365.94) || p.EquityToAssetRatio != Float(0.381) {
+		t.Errorf("Unexpected floats: %v %v", p.EarningsPerShare, p.EquityToAssetRatio)
+	}
+	if p.MaterialChangesInSubsidiaries != (NullBool{Bool: false, Valid: true}) || !p.RetrospectiveRestatement.Bool {
+		t.Errorf("Unexpected flags: %v %v", p.MaterialChangesInSubsidiaries, p.RetrospectiveRestatement)
+	}
+	if p.TypeOfCurrentPeriod != PeriodFY || !p.TypeOfDocument.IsFinancialStatements() ||
+		!p.TypeOfDocument.IsConsolidated() || p.TypeOfDocument.AccountingStandard() != AccountingStandardIFRS {
+		t.Errorf("Unexpected document type: %s %s", p.TypeOfDocument, p.TypeOfCurrentPeriod)
+	}
+}
+
+func TestStatementParseReportsBadFields(t *testing.T) {
+	st := Statement{NetSales: "1.5", EarningsPerShare: "abc", DisclosedDate: "2024-05-08"}
+	p, err := st.Parse()
+	if err == nil {
+		t.Fatal("Expected error for unparsable values")
+	}
+	// 整数でない金額は丸めずにエラーにする
+	if !strings.Contains(err.Error(), "NetSales") || !strings.Contains(err.Error(), "EarningsPerShare") {
+		t.Errorf("Expected field names in error, got: %v", err)
+	}
+	if p.NetSales.Valid || !p.DisclosedDate.Valid {
+		t.Errorf("Expected only the bad fields to be invalid: %+v", p)
+	}
+}
+
+func TestFormatCurrency(t *testing.T) {
+	cases := map[string]string{
+		"45095325000000": "45,095,325,000,000 JPY",
+		"-120000":        "-120,000 JPY",
+		"999":            "999 JPY",
+		"12.5":           "12.5 JPY",
+		"":               "",
+	}
+	for in, want := range cases {
+		if got := formatCurrency(in); got != want {
+			t.Errorf("formatCurrency(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -7,11 +7,32 @@ import (
 	"strings"
 )
 
+// Helper function: Formats an integer with thousands separators (e.g., 1234567 -> "1,234,567").
+func formatThousands(v int64) string {
+	digits := strconv.FormatInt(v, 10)
+	sign := ""
+	if v < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	var sb strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(d)
+	}
+	return sign + sb.String()
+}
+
 // Helper function: Formats a numeric string as currency in JPY.
+// Integer amounts get thousands separators; anything else is shown as-is.
 func formatCurrency(val string) string {
 	if val == "" {
 		return ""
 	}
+	if n, err := parseNullInt64(val); err == nil && n.Valid {
+		return formatThousands(n.Int64) + " JPY"
+	}
 	return val + " JPY"
 }
 
@@ -40,6 +61,9 @@ func formatShares(val string) string {
 	if val == "" {
 		return ""
 	}
+	if n, err := parseNullInt64(val); err == nil && n.Valid {
+		return formatThousands(n.Int64) + " shares"
+	}
 	return val + " shares"
 }
 
@@ -2,59 +2,75 @@ package jquants
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"net/url"
-	"os"
+	"strings"
 	"time"
 )
 
-// 必ずgitignoreする
-const tokenFilePath = "tokens.json"
-
-type tokenData struct {
+// Tokens は保存対象のトークン情報
+type Tokens struct {
 	RefreshToken       string    `json:"refresh_token"`
 	RefreshTokenExpiry time.Time `json:"refresh_token_expiry"`
 	IDToken            string    `json:"id_token"`
 	IDTokenExpiry      time.Time `json:"id_token_expiry"`
 }
 
-func loadTokens() (*tokenData, error) {
-	f, err := os.Open(tokenFilePath)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
+// TokenStore はトークンの読み書きを抽象化するインターフェース
+type TokenStore interface {
+	Load() (*Tokens, error)
+	Save(t *Tokens) error
+}
 
-	var t tokenData
-	if err := json.NewDecoder(f).Decode(&t); err != nil {
-		return nil, err
-	}
-	return &t, nil
+func isExpiringOrExpired(now, exp time.Time, threshold time.Duration) bool {
+	return !now.Add(threshold).Before(exp)
 }
 
-func saveTokens(t *tokenData) {
-	f, err := os.OpenFile(tokenFilePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+const (
+	// J-Quants のドキュメント上の有効期限。トークンから期限が読めないときに使う
+	refreshTokenLifetime = 7 * 24 * time.Hour
+	idTokenLifetime      = 24 * time.Hour
+)
+
+// jwtExpiry は JWT のペイロードの exp クレームを読んで期限を返す。
+// 署名は検証しない (期限の目安として使うだけで、トークンの正当性はサーバが判断する)
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
 	if err != nil {
-		log.Printf("[ERROR] Failed to open tokens.json: %v\n", err)
-		return
+		return time.Time{}, false
 	}
-	defer f.Close()
-
-	if err := json.NewEncoder(f).Encode(t); err != nil {
-		log.Printf("[ERROR] Failed to write tokens.json: %v\n", err)
+	var claims struct {
+		Exp json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == "" {
+		return time.Time{}, false
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil || exp <= 0 {
+		return time.Time{}, false
 	}
+	return time.Unix(int64(exp), 0), true
 }
 
-func isExpiringOrExpired(exp time.Time, threshold time.Duration) bool {
-	return time.Now().Add(threshold).After(exp)
+// tokenExpiry はトークンの期限を返す。JWT として読めなければ発行時刻 + lifetime とみなす
+func (c *JQuantsClient) tokenExpiry(token string, lifetime time.Duration) time.Time {
+	if exp, ok := jwtExpiry(token); ok {
+		return exp
+	}
+	return c.clock.Now().Add(lifetime)
 }
 
-func getRefreshTokenByCredentials(mail, pass string) (string, time.Time, error) {
-	apiURL := "https://api.jquants.com/v1/token/auth_user"
+func (c *JQuantsClient) getRefreshTokenByCredentials(ctx context.Context, mail, pass string) (string, time.Time, error) {
+	apiURL := c.endpoint("/token/auth_user")
 	body := map[string]string{
 		"mailaddress": mail,
 		"password":    pass,
@@ -64,62 +80,46 @@ func getRefreshTokenByCredentials(mail, pass string) (string, time.Time, error)
 		return "", time.Time{}, err
 	}
 
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(b))
+	resBody, err := c.do(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 	if err != nil {
-		return "", time.Time{}, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", time.Time{}, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		resBody, _ := io.ReadAll(resp.Body)
-		return "", time.Time{}, fmt.Errorf("failed to get refresh token: status=%d, body=%s", resp.StatusCode, string(resBody))
+		return "", time.Time{}, fmt.Errorf("failed to get refresh token: %w", err)
 	}
 
 	var result struct {
 		RefreshToken string `json:"refreshToken"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(resBody, &result); err != nil {
 		return "", time.Time{}, err
 	}
 
 	log.Println("[INFO] Successfully obtained refresh token from auth_user.")
-	return result.RefreshToken, time.Now().Add(7 * 24 * time.Hour), nil
+	return result.RefreshToken, c.tokenExpiry(result.RefreshToken, refreshTokenLifetime), nil
 }
 
-func getIDTokenByRefreshToken(refreshToken string) (string, time.Time, error) {
-	apiURL := "https://api.jquants.com/v1/token/auth_refresh?refreshtoken=" + url.QueryEscape(refreshToken)
+func (c *JQuantsClient) getIDTokenByRefreshToken(ctx context.Context, refreshToken string) (string, time.Time, error) {
+	apiURL := c.endpoint("/token/auth_refresh?refreshtoken=" + url.QueryEscape(refreshToken))
 
-	req, err := http.NewRequest("POST", apiURL, nil)
+	resBody, err := c.do(ctx, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "POST", apiURL, nil)
+	})
 	if err != nil {
-		return "", time.Time{}, err
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", time.Time{}, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		resBody, _ := io.ReadAll(resp.Body)
-		return "", time.Time{}, fmt.Errorf("failed to get ID token: status=%d, body=%s", resp.StatusCode, string(resBody))
+		return "", time.Time{}, fmt.Errorf("failed to get ID token: %w", err)
 	}
 
 	var result struct {
 		IDToken string `json:"idToken"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(resBody, &result); err != nil {
 		return "", time.Time{}, err
 	}
 
 	log.Println("[INFO] Successfully obtained ID token from auth_refresh.")
-	return result.IDToken, time.Now().Add(24 * time.Hour), nil
-}
\ No newline at end of file
+	return result.IDToken, c.tokenExpiry(result.IDToken, idTokenLifetime), nil
+}
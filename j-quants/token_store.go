@@ -0,0 +1,223 @@
+package jquants
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// defaultTokenFilePath はトークンファイルのデフォルトの保存先。必ずgitignoreする
+const defaultTokenFilePath = "tokens.json"
+
+const (
+	// tokenKeySaltSize は暗号化したトークンファイルの先頭に置くソルトの長さ
+	tokenKeySaltSize = 16
+	// scrypt のパラメータ。鍵の導出は読み書きのたびではなくソルトが変わったときだけ行う
+	tokenKeyScryptN = 1 << 15
+	tokenKeyScryptR = 8
+	tokenKeyScryptP = 1
+)
+
+// FileTokenStore はトークンを JSON ファイルに保存する TokenStore。
+// 書き込みは一時ファイル + rename で行い、別プロセスとの競合はロックファイルで防ぐ
+type FileTokenStore struct {
+	path       string
+	passphrase string // 空でなければ暗号化して保存する
+
+	// mu は salt と aead を保護する。aead は salt と passphrase から導出した鍵のもの
+	mu   sync.Mutex
+	salt []byte
+	aead cipher.AEAD
+}
+
+// NewFileTokenStore は path にトークンを保存する TokenStore を返す
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// NewEncryptedFileTokenStore は passphrase から scrypt で導出した鍵で AES-GCM 暗号化して保存する TokenStore を返す。
+// ファイルはソルト, nonce, 暗号文の順に並べる
+func NewEncryptedFileTokenStore(path, passphrase string) (*FileTokenStore, error) {
+	if passphrase == "" {
+		return nil, errors.New("token encryption key is empty")
+	}
+	return &FileTokenStore{path: path, passphrase: passphrase}, nil
+}
+
+// Path は保存先のファイルパスを返す
+func (s *FileTokenStore) Path() string {
+	return s.path
+}
+
+func (s *FileTokenStore) Load() (*Tokens, error) {
+	unlock, err := lockFile(s.path+".lock", false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	if s.passphrase != "" {
+		if b, err = s.decrypt(b); err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", s.path, err)
+		}
+	}
+
+	var t Tokens
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	return &t, nil
+}
+
+func (s *FileTokenStore) Save(t *Tokens) error {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if s.passphrase != "" {
+		if b, err = s.encrypt(b); err != nil {
+			return err
+		}
+	}
+
+	unlock, err := lockFile(s.path+".lock", true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// 途中で落ちても壊れたファイルが残らないよう、一時ファイルに書いてから置き換える
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *FileTokenStore) encrypt(plain []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 読み込んだファイルのソルトがあれば使い回し、無ければ新しく作る
+	salt := s.salt
+	if salt == nil {
+		salt = make([]byte, tokenKeySaltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, err
+		}
+	}
+	aead, err := s.cipherFor(salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	out := append(append([]byte{}, salt...), nonce...)
+	return aead.Seal(out, nonce, plain, nil), nil
+}
+
+func (s *FileTokenStore) decrypt(b []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(b) < tokenKeySaltSize {
+		return nil, errors.New("ciphertext too short")
+	}
+	aead, err := s.cipherFor(b[:tokenKeySaltSize])
+	if err != nil {
+		return nil, err
+	}
+	b = b[tokenKeySaltSize:]
+	n := aead.NonceSize()
+	if len(b) < n {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, b[:n], b[n:], nil)
+}
+
+// cipherFor は salt から導出した鍵の AEAD を返す。同じソルトなら前回の結果を使う。mu を取ってから呼ぶ
+func (s *FileTokenStore) cipherFor(salt []byte) (cipher.AEAD, error) {
+	if s.aead != nil && bytes.Equal(s.salt, salt) {
+		return s.aead, nil
+	}
+	key, err := scrypt.Key([]byte(s.passphrase), salt, tokenKeyScryptN, tokenKeyScryptR, tokenKeyScryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s.salt = append([]byte{}, salt...)
+	s.aead = aead
+	return aead, nil
+}
+
+// MemoryTokenStore はメモリ上にだけトークンを保持する TokenStore。テストや使い捨ての処理向け
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens *Tokens
+}
+
+// NewMemoryTokenStore は t を初期値に持つ MemoryTokenStore を返す。t は nil でもよい
+func NewMemoryTokenStore(t *Tokens) *MemoryTokenStore {
+	s := &MemoryTokenStore{}
+	if t != nil {
+		copied := *t
+		s.tokens = &copied
+	}
+	return s
+}
+
+func (s *MemoryTokenStore) Load() (*Tokens, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens == nil {
+		return nil, os.ErrNotExist
+	}
+	copied := *s.tokens
+	return &copied, nil
+}
+
+func (s *MemoryTokenStore) Save(t *Tokens) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *t
+	s.tokens = &copied
+	return nil
+}
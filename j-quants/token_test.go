@@ -2,10 +2,16 @@ package jquants
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -17,61 +23,140 @@ func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 	return f(req)
 }
 
-func TestSaveAndLoadTokens(t *testing.T) {
-	// 一時ディレクトリに移動してからテスト実施
-	tmpDir, err := os.MkdirTemp("", "jquants_test")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.RemoveAll(tmpDir)
+// newTestClient は指定の RoundTripper を使うテスト用クライアントを返す
+func newTestClient(rt http.RoundTripper, opts ...Option) *JQuantsClient {
+	opts = append([]Option{
+		WithHTTPClient(&http.Client{Transport: rt}),
+		WithTokenStore(NewMemoryTokenStore(nil)),
+	}, opts...)
+	return newClient(opts...)
+}
 
-	origWd, err := os.Getwd()
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.Chdir(origWd)
+func TestFileTokenStoreSaveAndLoad(t *testing.T) {
+	t.Parallel()
 
-	if err := os.Chdir(tmpDir); err != nil {
-		t.Fatal(err)
-	}
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	store := NewFileTokenStore(path)
 
 	now := time.Now()
-	token := &tokenData{
+	token := &Tokens{
 		RefreshToken:       "test_rt",
 		RefreshTokenExpiry: now.Add(7 * 24 * time.Hour),
 		IDToken:            "test_it",
 		IDTokenExpiry:      now.Add(24 * time.Hour),
 	}
 
-	saveTokens(token)
+	if err := store.Save(token); err != nil {
+		t.Fatalf("Failed to save tokens: %v", err)
+	}
 
-	loaded, err := loadTokens()
+	loaded, err := store.Load()
 	if err != nil {
 		t.Fatalf("Failed to load tokens: %v", err)
 	}
 	if loaded.RefreshToken != token.RefreshToken || loaded.IDToken != token.IDToken {
 		t.Error("Saved tokens and loaded tokens do not match")
 	}
+
+	// 一時ファイルが残っていないこと
+	matches, _ := filepath.Glob(path + ".tmp*")
+	if len(matches) != 0 {
+		t.Errorf("Expected no temp files left, got: %v", matches)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected token file with mode 0600, got: %v (err=%v)", info.Mode().Perm(), err)
+	}
+}
+
+func TestEncryptedFileTokenStore(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "tokens.enc")
+	store, err := NewEncryptedFileTokenStore(path, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(&Tokens{RefreshToken: "test_rt", IDToken: "test_it"}); err != nil {
+		t.Fatalf("Failed to save tokens: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("test_rt")) {
+		t.Error("Expected tokens to be encrypted at rest")
+	}
+
+	loaded, err := store.Load()
+	if err != nil || loaded.RefreshToken != "test_rt" {
+		t.Fatalf("Failed to load encrypted tokens: %+v (err=%v)", loaded, err)
+	}
+
+	wrongKey, _ := NewEncryptedFileTokenStore(path, "wrong")
+	if _, err := wrongKey.Load(); err == nil {
+		t.Error("Expected error when loading with the wrong key")
+	}
+
+	// 別のストアでも同じパスフレーズなら読める。ソルトはファイルごとに違う
+	other := filepath.Join(t.TempDir(), "tokens.enc")
+	otherStore, _ := NewEncryptedFileTokenStore(other, "secret")
+	if err := otherStore.Save(&Tokens{RefreshToken: "test_rt", IDToken: "test_it"}); err != nil {
+		t.Fatal(err)
+	}
+	otherRaw, err := os.ReadFile(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(raw[:tokenKeySaltSize], otherRaw[:tokenKeySaltSize]) {
+		t.Error("Expected each file to have its own salt")
+	}
+	reopened, _ := NewEncryptedFileTokenStore(other, "secret")
+	if loaded, err := reopened.Load(); err != nil || loaded.IDToken != "test_it" {
+		t.Errorf("Failed to load with a new store: %+v (err=%v)", loaded, err)
+	}
+}
+
+func TestFileTokenStoreConcurrentSave(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// プロセスが別々でも同じになるよう、ストアは goroutine ごとに作る
+			store := NewFileTokenStore(path)
+			if err := store.Save(&Tokens{IDToken: fmt.Sprintf("it_%d", i)}); err != nil {
+				t.Error(err)
+			}
+			if _, err := store.Load(); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
 }
 
 func TestIsExpiringOrExpired(t *testing.T) {
 	// トークンの期限が未来(1分後)で、threshold が大きいと expiring と判定される
-	future := time.Now().Add(1 * time.Minute)
-	if !isExpiringOrExpired(future, 2*time.Minute) {
+	now := time.Now()
+	future := now.Add(1 * time.Minute)
+	if !isExpiringOrExpired(now, future, 2*time.Minute) {
 		t.Error("Expected token to be expiring with threshold 2m")
 	}
 	// threshold 0 なら expiring ではない
-	if isExpiringOrExpired(future, 0) {
+	if isExpiringOrExpired(now, future, 0) {
 		t.Error("Expected token not to be expiring with threshold 0")
 	}
 }
 
 func TestGetRefreshTokenByCredentials(t *testing.T) {
-	// HTTP リクエストをフックするために http.DefaultTransport を差し替える
-	origTransport := http.DefaultTransport
-	defer func() { http.DefaultTransport = origTransport }()
+	t.Parallel()
 
-	http.DefaultTransport = RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+	// HTTP リクエストをフックするためにクライアント専用の Transport を差し込む
+	c := newTestClient(RoundTripFunc(func(req *http.Request) (*http.Response, error) {
 		if req.URL.String() != "https://api.jquants.com/v1/token/auth_user" {
 			return nil, fmt.Errorf("unexpected URL: %s", req.URL.String())
 		}
@@ -81,9 +166,9 @@ func TestGetRefreshTokenByCredentials(t *testing.T) {
 			Body:       io.NopCloser(bytes.NewBufferString(respBody)),
 			Header:     make(http.Header),
 		}, nil
-	})
+	}))
 
-	token, exp, err := getRefreshTokenByCredentials("dummy@mail", "dummy_pass")
+	token, exp, err := c.getRefreshTokenByCredentials(context.Background(), "dummy@mail", "dummy_pass")
 	if err != nil {
 		t.Fatalf("Error in getRefreshTokenByCredentials: %v", err)
 	}
@@ -96,10 +181,9 @@ func TestGetRefreshTokenByCredentials(t *testing.T) {
 }
 
 func TestGetIDTokenByRefreshToken(t *testing.T) {
-	origTransport := http.DefaultTransport
-	defer func() { http.DefaultTransport = origTransport }()
+	t.Parallel()
 
-	http.DefaultTransport = RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+	c := newTestClient(RoundTripFunc(func(req *http.Request) (*http.Response, error) {
 		expectedURL := "https://api.jquants.com/v1/token/auth_refresh?refreshtoken=test_rt"
 		if req.URL.String() != expectedURL {
 			return nil, fmt.Errorf("unexpected URL: %s", req.URL.String())
@@ -110,9 +194,9 @@ func TestGetIDTokenByRefreshToken(t *testing.T) {
 			Body:       io.NopCloser(bytes.NewBufferString(respBody)),
 			Header:     make(http.Header),
 		}, nil
-	})
+	}))
 
-	token, exp, err := getIDTokenByRefreshToken("test_rt")
+	token, exp, err := c.getIDTokenByRefreshToken(context.Background(), "test_rt")
 	if err != nil {
 		t.Fatalf("Error in getIDTokenByRefreshToken: %v", err)
 	}
@@ -123,3 +207,162 @@ func TestGetIDTokenByRefreshToken(t *testing.T) {
 		t.Error("Expected expiry time to be in the future")
 	}
 }
+
+// makeJWT は exp クレームだけを持つ署名なしのテスト用 JWT を作る
+func makeJWT(exp time.Time) string {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"none"}`))
+	payload := enc.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix())))
+	return header + "." + payload + ".sig"
+}
+
+func TestJWTExpiry(t *testing.T) {
+	exp := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)
+	got, ok := jwtExpiry(makeJWT(exp))
+	if !ok || !got.Equal(exp) {
+		t.Errorf("Expected %v, got %v (ok=%v)", exp, got, ok)
+	}
+	for _, invalid := range []string{"opaque-token", "a.b.c", "a." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"x"}`)) + ".c"} {
+		if _, ok := jwtExpiry(invalid); ok {
+			t.Errorf("Expected %q not to have an expiry", invalid)
+		}
+	}
+}
+
+func TestIDTokenExpiryFromJWT(t *testing.T) {
+	t.Parallel()
+
+	exp := time.Now().Add(3 * time.Hour).Truncate(time.Second)
+	c := newTestClient(RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		respBody := fmt.Sprintf(`{"idToken": %q}`, makeJWT(exp))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(respBody)),
+			Header:     make(http.Header),
+		}, nil
+	}))
+
+	_, got, err := c.getIDTokenByRefreshToken(context.Background(), "test_rt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(exp) {
+		t.Errorf("Expected expiry from JWT exp claim %v, got %v", exp, got)
+	}
+}
+
+func TestRunTokenRefresherWithShortLivedToken(t *testing.T) {
+	t.Parallel()
+
+	// 有効期間 (2分) が expiryMargin (5分) より短い ID トークンを返すサーバー
+	clock := &fakeClock{now: time.Now()}
+	var refreshCalls atomic.Int32
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshCalls.Add(1)
+		fmt.Fprintf(w, `{"idToken": %q}`, makeJWT(clock.Now().Add(2*time.Minute)))
+	}), WithClock(clock))
+	c.IDTokenExpiry = clock.Now().Add(2 * time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var waits []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		if len(waits) == 3 {
+			cancel()
+		}
+		return clock.Sleep(ctx, d)
+	}
+
+	if err := c.RunTokenRefresher(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+	// 待ち時間が 0 以下にならず、最短の間隔をあけて取り直す
+	for _, d := range waits {
+		if d < tokenRefresherMinInterval {
+			t.Errorf("Expected to wait at least %s between refreshes, got %v", tokenRefresherMinInterval, waits)
+			break
+		}
+	}
+	if n := refreshCalls.Load(); n != 2 {
+		t.Errorf("Expected 2 background refreshes, got %d", n)
+	}
+}
+
+func TestExpiryMarginTriggersRefresh(t *testing.T) {
+	t.Parallel()
+
+	var refreshCalls atomic.Int32
+	c := newServerClient(t, refreshCountingHandler(&refreshCalls), WithTokenExpiryMargin(10*time.Minute))
+	// 期限まで5分しかないので、マージン10分なら取り直す
+	c.IDToken = "new_it"
+	c.IDTokenExpiry = time.Now().Add(5 * time.Minute)
+
+	if _, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"}); err != nil {
+		t.Fatal(err)
+	}
+	if refreshCalls.Load() != 1 {
+		t.Errorf("Expected token to be refreshed within the margin, got %d refreshes", refreshCalls.Load())
+	}
+}
+
+func TestShortLivedTokenIsNotRefreshedOnEveryRequest(t *testing.T) {
+	t.Parallel()
+
+	// 有効期間 (2分) が expiryMargin (5分) より短い ID トークンを返すサーバー
+	var refreshCalls atomic.Int32
+	idToken := makeJWT(time.Now().Add(2 * time.Minute))
+	c := newServerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token/auth_refresh":
+			refreshCalls.Add(1)
+			fmt.Fprintf(w, `{"idToken": %q}`, idToken)
+		default:
+			if r.Header.Get("Authorization") != "Bearer "+idToken {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"daily_quotes":[{"Date":"2024-01-04","Code":"72030"}]}`))
+		}
+	}))
+	c.IDToken = ""
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetDailyQuotes(GetDailyQuotesParams{Code: "7203"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := refreshCalls.Load(); n != 1 {
+		t.Errorf("Expected 1 refresh for a token shorter-lived than the margin, got %d", n)
+	}
+}
+
+func TestRunTokenRefresher(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Now()}
+	var refreshCalls atomic.Int32
+	c := newServerClient(t, refreshCountingHandler(&refreshCalls), WithClock(clock))
+	c.IDTokenExpiry = clock.Now().Add(time.Hour)
+	c.RefreshExp = clock.Now().Add(7 * 24 * time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var waits []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		// 2回目の待機に入ったら (= 1回取り直したら) 終了
+		if len(waits) == 2 {
+			cancel()
+		}
+		return clock.Sleep(ctx, d)
+	}
+
+	if err := c.RunTokenRefresher(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+	if refreshCalls.Load() != 1 {
+		t.Errorf("Expected 1 background refresh, got %d", refreshCalls.Load())
+	}
+	if waits[0] != time.Hour-defaultExpiryMargin {
+		t.Errorf("Expected to wait until the margin before expiry, got %v", waits[0])
+	}
+}
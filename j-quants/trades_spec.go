@@ -0,0 +1,219 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// TradesSpecSection は投資部門別売買状況の市場区分
+type TradesSpecSection string
+
+const (
+	TradesSpecTSEPrime    TradesSpecSection = "TSEPrime"
+	TradesSpecTSEStandard TradesSpecSection = "TSEStandard"
+	TradesSpecTSEGrowth   TradesSpecSection = "TSEGrowth"
+	TradesSpecTokyoNagoya TradesSpecSection = "TokyoNagoya" // 東証および名証
+	// 2022/4 の市場再編までの区分
+	TradesSpecTSE1st     TradesSpecSection = "TSE1st"
+	TradesSpecTSE2nd     TradesSpecSection = "TSE2nd"
+	TradesSpecTSEMothers TradesSpecSection = "TSEMothers"
+	TradesSpecTSEJASDAQ  TradesSpecSection = "TSEJASDAQ"
+)
+
+// InvestorCategory は投資部門。J-Quants のフィールド名の接頭辞と同じ値にしている
+type InvestorCategory string
+
+const (
+	InvestorProprietary                InvestorCategory = "Proprietary"                // 自己計
+	InvestorBrokerage                  InvestorCategory = "Brokerage"                  // 委託計
+	InvestorTotal                      InvestorCategory = "Total"                      // 総計
+	InvestorIndividuals                InvestorCategory = "Individuals"                // 個人
+	InvestorForeigners                 InvestorCategory = "Foreigners"                 // 海外投資家
+	InvestorSecuritiesCos              InvestorCategory = "SecuritiesCos"              // 証券会社
+	InvestorInvestmentTrusts           InvestorCategory = "InvestmentTrusts"           // 投資信託
+	InvestorBusinessCos                InvestorCategory = "BusinessCos"                // 事業法人
+	InvestorOtherCos                   InvestorCategory = "OtherCos"                   // その他法人等
+	InvestorInsuranceCos               InvestorCategory = "InsuranceCos"               // 生保・損保
+	InvestorCityBKsRegionalBKsEtc      InvestorCategory = "CityBKsRegionalBKsEtc"      // 都銀・地銀等
+	InvestorTrustBanks                 InvestorCategory = "TrustBanks"                 // 信託銀行
+	InvestorOtherFinancialInstitutions InvestorCategory = "OtherFinancialInstitutions" // その他金融機関
+)
+
+// InvestorCategories は J-Quants が返す全投資部門
+var InvestorCategories = []InvestorCategory{
+	InvestorProprietary,
+	InvestorBrokerage,
+	InvestorTotal,
+	InvestorIndividuals,
+	InvestorForeigners,
+	InvestorSecuritiesCos,
+	InvestorInvestmentTrusts,
+	InvestorBusinessCos,
+	InvestorOtherCos,
+	InvestorInsuranceCos,
+	InvestorCityBKsRegionalBKsEtc,
+	InvestorTrustBanks,
+	InvestorOtherFinancialInstitutions,
+}
+
+// InvestorFlow は1つの投資部門の売買代金 (千円)
+type InvestorFlow struct {
+	Sales     float64 // 売り
+	Purchases float64 // 買い
+	Total     float64 // 合計
+	Balance   float64 // 差引 (買い - 売り)
+}
+
+// TradesSpec は /markets/trades_spec の1市場区分・1週分の投資部門別売買状況
+type TradesSpec struct {
+	PublishedDate string
+	StartDate     string
+	EndDate       string
+	Section       TradesSpecSection
+	Flows         map[InvestorCategory]InvestorFlow
+}
+
+// UnmarshalJSON は "IndividualsSales" のような平たいフィールドを投資部門ごとにまとめる
+func (t *TradesSpec) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	var header struct {
+		PublishedDate string            `json:"PublishedDate"`
+		StartDate     string            `json:"StartDate"`
+		EndDate       string            `json:"EndDate"`
+		Section       TradesSpecSection `json:"Section"`
+	}
+	if err := json.Unmarshal(b, &header); err != nil {
+		return err
+	}
+	t.PublishedDate = header.PublishedDate
+	t.StartDate = header.StartDate
+	t.EndDate = header.EndDate
+	t.Section = header.Section
+	t.Flows = make(map[InvestorCategory]InvestorFlow, len(InvestorCategories))
+
+	for _, cat := range InvestorCategories {
+		var flow InvestorFlow
+		found := false
+		for suffix, dst := range map[string]*float64{
+			"Sales":     &flow.Sales,
+			"Purchases": &flow.Purchases,
+			"Total":     &flow.Total,
+			"Balance":   &flow.Balance,
+		} {
+			v, ok := raw[string(cat)+suffix]
+			if !ok || string(v) == "null" {
+				continue
+			}
+			if err := json.Unmarshal(v, dst); err != nil {
+				return fmt.Errorf("invalid %s%s: %w", cat, suffix, err)
+			}
+			found = true
+		}
+		if found {
+			t.Flows[cat] = flow
+		}
+	}
+	return nil
+}
+
+// tradesSpecResponse : JSON全体を受け取るための構造
+type tradesSpecResponse struct {
+	TradesSpec    []TradesSpec `json:"trades_spec"`
+	PaginationKey string       `json:"pagination_key"`
+}
+
+// GetTradesSpecParams : クエリパラメータ。すべて省略すると全期間・全区分
+type GetTradesSpecParams struct {
+	Section TradesSpecSection
+	From    string
+	To      string
+}
+
+// GetTradesSpec は /markets/trades_spec を全ページ取得し、[]TradesSpec を返す
+func (c *JQuantsClient) GetTradesSpec(params GetTradesSpecParams) ([]TradesSpec, error) {
+	return c.GetTradesSpecWithContext(context.Background(), params)
+}
+
+// GetTradesSpecWithContext は GetTradesSpec の context 対応版。
+// キャンセル時はそれまでに取得できた分とエラーを返す
+func (c *JQuantsClient) GetTradesSpecWithContext(ctx context.Context, params GetTradesSpecParams) ([]TradesSpec, error) {
+	return DoPaginatedGetWithContext[TradesSpec](ctx, c, c.endpoint("/markets/trades_spec"), params.values(), extractTradesSpec)
+}
+
+// StreamTradesSpec は /markets/trades_spec を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamTradesSpec(ctx context.Context, params GetTradesSpecParams, handle PageHandler[TradesSpec]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/markets/trades_spec"), params.values(), extractTradesSpec, handle)
+}
+
+func (params GetTradesSpecParams) values() url.Values {
+	q := url.Values{}
+	if params.Section != "" {
+		q.Set("section", string(params.Section))
+	}
+	if params.From != "" {
+		q.Set("from", params.From)
+	}
+	if params.To != "" {
+		q.Set("to", params.To)
+	}
+	return q
+}
+
+func extractTradesSpec(respBytes []byte) ([]TradesSpec, string, error) {
+	var r tradesSpecResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal trades_spec: %w", err)
+	}
+	return r.TradesSpec, r.PaginationKey, nil
+}
+
+// FlowPoint は投資部門別の時系列の1点 (1週分)
+type FlowPoint struct {
+	StartDate     string
+	EndDate       string
+	PublishedDate string
+	InvestorFlow
+}
+
+// PivotTradesSpec は指定した市場区分の結果を、投資部門ごとの週次の時系列 (StartDate の昇順) に組み替える。
+// 同じ週が訂正で複数回公表されている場合は、最も新しい公表分を使う
+func PivotTradesSpec(specs []TradesSpec, section TradesSpecSection) map[InvestorCategory][]FlowPoint {
+	latest := make(map[string]TradesSpec)
+	for _, s := range specs {
+		if s.Section != section {
+			continue
+		}
+		key := s.StartDate + "/" + s.EndDate
+		if prev, ok := latest[key]; !ok || s.PublishedDate > prev.PublishedDate {
+			latest[key] = s
+		}
+	}
+
+	weeks := make([]TradesSpec, 0, len(latest))
+	for _, s := range latest {
+		weeks = append(weeks, s)
+	}
+	sort.Slice(weeks, func(i, j int) bool {
+		return weeks[i].StartDate < weeks[j].StartDate
+	})
+
+	result := make(map[InvestorCategory][]FlowPoint)
+	for _, s := range weeks {
+		for cat, flow := range s.Flows {
+			result[cat] = append(result[cat], FlowPoint{
+				StartDate:     s.StartDate,
+				EndDate:       s.EndDate,
+				PublishedDate: s.PublishedDate,
+				InvestorFlow:  flow,
+			})
+		}
+	}
+	return result
+}
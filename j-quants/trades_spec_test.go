@@ -0,0 +1,50 @@
+package jquants
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const tradesSpecJSON = `{"trades_spec":[
+	{"PublishedDate":"2024-01-11","StartDate":"2024-01-04","EndDate":"2024-01-05","Section":"TSEPrime",
+	 "ForeignersSales":1000.0,"ForeignersPurchases":1500.0,"ForeignersTotal":2500.0,"ForeignersBalance":500.0,
+	 "IndividualsSales":800.0,"IndividualsPurchases":600.0,"IndividualsTotal":1400.0,"IndividualsBalance":-200.0},
+	{"PublishedDate":"2024-01-18","StartDate":"2024-01-09","EndDate":"2024-01-12","Section":"TSEPrime",
+	 "ForeignersSales":900.0,"ForeignersPurchases":1000.0,"ForeignersTotal":1900.0,"ForeignersBalance":100.0},
+	{"PublishedDate":"2024-01-19","StartDate":"2024-01-09","EndDate":"2024-01-12","Section":"TSEPrime",
+	 "ForeignersSales":900.0,"ForeignersPurchases":1100.0,"ForeignersTotal":2000.0,"ForeignersBalance":200.0},
+	{"PublishedDate":"2024-01-11","StartDate":"2024-01-04","EndDate":"2024-01-05","Section":"TSEGrowth",
+	 "ForeignersSales":10.0,"ForeignersPurchases":20.0,"ForeignersTotal":30.0,"ForeignersBalance":10.0}
+]}`
+
+func TestPivotTradesSpec(t *testing.T) {
+	specs, _, err := extractTradesSpec([]byte(tradesSpecJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flow := specs[0].Flows[InvestorIndividuals]; flow.Balance != -200 || flow.Purchases != 600 {
+		t.Errorf("Unexpected individuals flow: %+v", flow)
+	}
+	if _, ok := specs[0].Flows[InvestorTrustBanks]; ok {
+		t.Error("Expected categories missing from the response to be absent")
+	}
+
+	series := PivotTradesSpec(specs, TradesSpecTSEPrime)
+	foreigners := series[InvestorForeigners]
+	if len(foreigners) != 2 {
+		t.Fatalf("Expected 2 weeks, got %+v", foreigners)
+	}
+	if foreigners[0].StartDate != "2024-01-04" || foreigners[1].Balance != 200 {
+		t.Errorf("Expected weeks in order with the revised figure, got %+v", foreigners)
+	}
+	if len(series[InvestorIndividuals]) != 1 {
+		t.Errorf("Unexpected individuals series: %+v", series[InvestorIndividuals])
+	}
+}
+
+func TestTradesSpecUnmarshalInvalid(t *testing.T) {
+	var s TradesSpec
+	if err := json.Unmarshal([]byte(`{"ForeignersSales":"abc"}`), &s); err == nil {
+		t.Error("Expected error for a non-numeric value")
+	}
+}
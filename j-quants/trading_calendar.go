@@ -0,0 +1,74 @@
+package jquants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// HolidayDivision は休日区分
+type HolidayDivision string
+
+const (
+	HolidayDivisionNonBusinessDay        HolidayDivision = "0" // 非営業日
+	HolidayDivisionBusinessDay           HolidayDivision = "1" // 営業日
+	HolidayDivisionHalfDay               HolidayDivision = "2" // 東証半日立会日
+	HolidayDivisionHolidayTradingSession HolidayDivision = "3" // 非営業日 (祝日取引あり)
+)
+
+// TradingCalendarDay は /markets/trading_calendar の1日分
+type TradingCalendarDay struct {
+	Date            string          `json:"Date"`
+	HolidayDivision HolidayDivision `json:"HolidayDivision"`
+}
+
+// tradingCalendarResponse : JSON全体を受け取るための構造
+type tradingCalendarResponse struct {
+	TradingCalendar []TradingCalendarDay `json:"trading_calendar"`
+	PaginationKey   string               `json:"pagination_key"`
+}
+
+// GetTradingCalendarParams : クエリパラメータ
+type GetTradingCalendarParams struct {
+	HolidayDivision HolidayDivision // 省略すると全区分
+	From            string
+	To              string
+}
+
+// GetTradingCalendar は /markets/trading_calendar を取得し、[]TradingCalendarDay を返す
+func (c *JQuantsClient) GetTradingCalendar(params GetTradingCalendarParams) ([]TradingCalendarDay, error) {
+	return c.GetTradingCalendarWithContext(context.Background(), params)
+}
+
+// GetTradingCalendarWithContext は GetTradingCalendar の context 対応版
+func (c *JQuantsClient) GetTradingCalendarWithContext(ctx context.Context, params GetTradingCalendarParams) ([]TradingCalendarDay, error) {
+	return DoPaginatedGetWithContext[TradingCalendarDay](ctx, c, c.endpoint("/markets/trading_calendar"), params.values(), extractTradingCalendar)
+}
+
+// StreamTradingCalendar は /markets/trading_calendar を1ページずつ handle に渡す
+func (c *JQuantsClient) StreamTradingCalendar(ctx context.Context, params GetTradingCalendarParams, handle PageHandler[TradingCalendarDay]) error {
+	return DoPaginatedEach(ctx, c, c.endpoint("/markets/trading_calendar"), params.values(), extractTradingCalendar, handle)
+}
+
+func (params GetTradingCalendarParams) values() url.Values {
+	q := url.Values{}
+	if params.HolidayDivision != "" {
+		q.Set("holidaydivision", string(params.HolidayDivision))
+	}
+	if params.From != "" {
+		q.Set("from", params.From)
+	}
+	if params.To != "" {
+		q.Set("to", params.To)
+	}
+	return q
+}
+
+func extractTradingCalendar(respBytes []byte) ([]TradingCalendarDay, string, error) {
+	var r tradingCalendarResponse
+	if err := json.Unmarshal(respBytes, &r); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal trading_calendar: %w", err)
+	}
+	return r.TradingCalendar, r.PaginationKey, nil
+}
@@ -1,22 +1,65 @@
 package main
 
 import (
+	"Go-AutoTrade/config"
 	jquants "Go-AutoTrade/j-quants"
 	"Go-AutoTrade/utils"
+	"Go-AutoTrade/warehouse"
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 )
 
 func main() {
+	syncOnly := flag.Bool("sync", false, "sync the local warehouse (WAREHOUSE_DIR) and exit")
+	syncFrom := flag.String("from", "", "first date to sync (YYYY-MM-DD). Defaults to the day after the last sync")
+	flag.Parse()
+
 	utils.InitLogger()
-	jqClient, err := jquants.New()
+
+	// Ctrl-C で進行中のリクエストをキャンセルし、途中終了させる
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	jqClient, err := jquants.NewWithContext(ctx)
 	if err != nil {
 		log.Fatalf("Failed to init JQuantsClient: %s", err)
 	}
 
-	res, _ := jqClient.GetStatements(jquants.GetStatementsParams{
-		Code: "9104",
-	})
+	// WAREHOUSE_DIR が設定されていれば、手元に保存したデータを優先して使う
+	var store *warehouse.Store
+	if dir := config.GlobalConfig.WarehouseDir; dir != "" {
+		if store, err = warehouse.Open(dir); err != nil {
+			log.Fatalf("Failed to open warehouse: %s", err)
+		}
+	}
+
+	if *syncOnly {
+		if store == nil {
+			log.Fatal("WAREHOUSE_DIR is not set")
+		}
+		opts := warehouse.SyncOptions{}
+		if *syncFrom != "" {
+			if opts.From, err = jquants.ParseDate(*syncFrom); err != nil {
+				log.Fatalf("Invalid -from: %s", err)
+			}
+		}
+		if _, err := store.Sync(ctx, jqClient, opts); err != nil {
+			log.Fatalf("Failed to sync warehouse: %s", err)
+		}
+		return
+	}
+
+	params := jquants.GetStatementsParams{Code: "9104"}
+	var res []jquants.Statement
+	if store != nil {
+		res, _ = store.Reader(jqClient).GetStatementsWithContext(ctx, params)
+	} else {
+		res, _ = jqClient.GetStatementsWithContext(ctx, params)
+	}
 
 	fmt.Println(jquants.GenerateStatementsReport(res))
 }
@@ -0,0 +1,215 @@
+package warehouse
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	jquants "Go-AutoTrade/j-quants"
+)
+
+// ErrNotInWarehouse は手元にデータが無く、取得元も設定されていないことを表す
+var ErrNotInWarehouse = errors.New("warehouse: data is not available locally")
+
+// Reader は JQuantsClient と同じ形のメソッドで、まず倉庫のデータを引き、足りなければ src から取得する。
+// src が nil なら倉庫だけを引き、足りない場合は ErrNotInWarehouse を返す
+type Reader struct {
+	store *Store
+	src   Source
+	now   func() time.Time
+}
+
+// Reader は倉庫を引く Reader を返す
+func (s *Store) Reader(src Source) *Reader {
+	return &Reader{store: s, src: src, now: time.Now}
+}
+
+// codeStatements は銘柄を指定して取得した財務情報と、取得した日
+type codeStatements struct {
+	FetchedOn  string              `json:"fetched_on"`
+	Statements []jquants.Statement `json:"statements"`
+}
+
+// GetDailyQuotesWithContext は日付指定、または銘柄と期間 (From/To) の指定であれば倉庫から返す。
+// 期間内の営業日が1日でも欠けていれば、まとめて src から取得する
+func (r *Reader) GetDailyQuotesWithContext(ctx context.Context, params jquants.GetDailyQuotesParams) ([]jquants.DailyQuote, error) {
+	code := func(q jquants.DailyQuote) string { return q.Code }
+	if params.Date != "" {
+		if d, err := jquants.ParseDate(params.Date); err == nil {
+			if items, ok := readLocal(r.store, DailyQuotes, []time.Time{d}, params.Code, code); ok {
+				return items, nil
+			}
+		}
+	} else if params.Code != "" && params.From != "" && params.To != "" {
+		if days, ok := r.tradingDays(params.From, params.To); ok {
+			if items, ok := readLocal(r.store, DailyQuotes, days, params.Code, code); ok {
+				return items, nil
+			}
+		}
+	}
+
+	if r.src == nil {
+		return nil, ErrNotInWarehouse
+	}
+	items, err := r.src.GetDailyQuotesWithContext(ctx, params)
+	if err == nil && params.Code == "" {
+		writeThrough(r, DailyQuotes, params.Date, items)
+	}
+	return items, err
+}
+
+// GetStatementsWithContext は日付指定であればその日の分を、銘柄指定であれば以前に取得した分に
+// 取得した日以降に Sync した日の分を足して倉庫から返す。銘柄の分は、取得した日から前営業日まで欠けなく Sync されている場合だけ使う
+func (r *Reader) GetStatementsWithContext(ctx context.Context, params jquants.GetStatementsParams) ([]jquants.Statement, error) {
+	code := func(st jquants.Statement) string { return st.LocalCode }
+	if params.Date != "" {
+		if d, err := jquants.ParseDate(params.Date); err == nil {
+			if items, ok := readLocal(r.store, Statements, []time.Time{d}, params.Code, code); ok {
+				return items, nil
+			}
+		}
+	} else if params.Code != "" {
+		if items, ok := r.localStatementsByCode(params.Code); ok {
+			return items, nil
+		}
+	}
+
+	if r.src == nil {
+		return nil, ErrNotInWarehouse
+	}
+	items, err := r.src.GetStatementsWithContext(ctx, params)
+	if err != nil {
+		return items, err
+	}
+	switch {
+	case params.Code == "":
+		writeThrough(r, Statements, params.Date, items)
+	case params.Date == "":
+		cs := codeStatements{FetchedOn: jquants.DateOf(r.now()).String(), Statements: items}
+		if err := writeGzipJSON(r.store.codePath(params.Code), cs); err != nil {
+			log.Printf("[WARN] Failed to save statements for %s to warehouse: %v", params.Code, err)
+		}
+	}
+	return items, nil
+}
+
+// GetListedInfoWithContext は日付指定であればその日の一覧を、日付が無ければ最後に Sync した一覧を倉庫から返す
+func (r *Reader) GetListedInfoWithContext(ctx context.Context, params jquants.GetListedInfoParams) ([]jquants.ListedInfo, error) {
+	code := func(li jquants.ListedInfo) string { return li.Code }
+	date := params.Date
+	if date == "" {
+		if wms, err := r.store.Watermarks(); err == nil {
+			date = wms[ListedInfo].Through
+		}
+	}
+	if d, err := jquants.ParseDate(date); err == nil {
+		if items, ok := readLocal(r.store, ListedInfo, []time.Time{d}, params.Code, code); ok {
+			return items, nil
+		}
+	}
+
+	if r.src == nil {
+		return nil, ErrNotInWarehouse
+	}
+	items, err := r.src.GetListedInfoWithContext(ctx, params)
+	if err == nil && params.Code == "" {
+		writeThrough(r, ListedInfo, params.Date, items)
+	}
+	return items, err
+}
+
+// localStatementsByCode は銘柄ごとに保存した財務情報に、取得した日以降に Sync した日の分を足して返す
+func (r *Reader) localStatementsByCode(code string) ([]jquants.Statement, bool) {
+	var cs codeStatements
+	if ok, err := readGzipJSON(r.store.codePath(code), &cs); !ok || err != nil {
+		return nil, false
+	}
+	fetched, err := jquants.ParseDate(cs.FetchedOn)
+	if err != nil {
+		return nil, false
+	}
+
+	// 取得した日 (取得後の開示があり得るので当日も含む) から前営業日まで、すべて Sync 済みである必要がある
+	cal, err := r.store.Calendar()
+	if err != nil {
+		return nil, false
+	}
+	today := jquants.DateOf(r.now()).Time
+	last, ok := cal.PrevTradingDay(today)
+	if !ok {
+		return nil, false
+	}
+	var days []time.Time
+	if !fetched.After(last) {
+		if !cal.Covers(fetched) {
+			return nil, false
+		}
+		days = cal.TradingDaysBetween(fetched, last)
+	}
+	newer, ok := readLocal(r.store, Statements, days, code, func(st jquants.Statement) string { return st.LocalCode })
+	if !ok {
+		return nil, false
+	}
+
+	seen := make(map[string]bool)
+	var result []jquants.Statement
+	for _, st := range append(cs.Statements, newer...) {
+		if seen[st.DisclosureNumber] {
+			continue
+		}
+		seen[st.DisclosureNumber] = true
+		result = append(result, st)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DisclosedDate+result[i].DisclosedTime < result[j].DisclosedDate+result[j].DisclosedTime
+	})
+	return result, true
+}
+
+// tradingDays は from から to までの営業日を返す。保存済みのカレンダーが範囲を含まなければ false
+func (r *Reader) tradingDays(from, to string) ([]time.Time, bool) {
+	f, err1 := jquants.ParseDate(from)
+	t, err2 := jquants.ParseDate(to)
+	if err1 != nil || err2 != nil {
+		return nil, false
+	}
+	cal, err := r.store.Calendar()
+	if err != nil || !cal.Covers(f) || !cal.Covers(t) {
+		return nil, false
+	}
+	return cal.TradingDaysBetween(f, t), true
+}
+
+// writeThrough は src から取得した1日分の全銘柄のデータを倉庫にも保存する。当日分は確定していないので保存しない
+func writeThrough[T any](r *Reader, ds Dataset, date string, items []T) {
+	d, err := jquants.ParseDate(date)
+	if err != nil || !d.Before(jquants.DateOf(r.now()).Time) {
+		return
+	}
+	if _, err := saveFetched(r.store, ds, d, items, nil); err != nil {
+		log.Printf("[WARN] Failed to save %s for %s to warehouse: %v", ds, date, err)
+	}
+}
+
+// readLocal は days の各日の分を読み、code に一致するものだけを返す。1日でも保存されていなければ false
+func readLocal[T any](s *Store, ds Dataset, days []time.Time, code string, codeOf func(T) string) ([]T, bool) {
+	var result []T
+	for _, d := range days {
+		items, ok, err := loadDate[T](s, ds, d)
+		if err != nil {
+			log.Printf("[WARN] Failed to read %s for %s from warehouse: %v", ds, jquants.DateOf(d), err)
+			return nil, false
+		}
+		if !ok {
+			return nil, false
+		}
+		for _, item := range items {
+			if matchCode(codeOf(item), code) {
+				result = append(result, item)
+			}
+		}
+	}
+	return result, true
+}
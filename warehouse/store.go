@@ -0,0 +1,209 @@
+// Package warehouse は J-Quants のデータを手元のファイルに溜めておく倉庫。
+// 日足・財務情報・上場銘柄一覧を日付ごとの gzip 圧縮した JSON ファイルに保存し、
+// Sync で足りない日付だけを取得する。Reader は JQuantsClient と同じ形のメソッドで、まず手元のデータを引く
+package warehouse
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	jquants "Go-AutoTrade/j-quants"
+)
+
+// Dataset は倉庫に保存するデータの種類
+type Dataset string
+
+const (
+	DailyQuotes Dataset = "daily_quotes"
+	Statements  Dataset = "statements"
+	ListedInfo  Dataset = "listed_info"
+)
+
+// AllDatasets は Sync の対象にできるすべてのデータ
+var AllDatasets = []Dataset{DailyQuotes, Statements, ListedInfo}
+
+// alwaysHasData は営業日であれば必ずデータがあるかどうかを返す。
+// そういうデータで空の結果が返ってきた場合は、まだ公開されていないかプランの制限とみなして保存しない
+func (ds Dataset) alwaysHasData() bool {
+	return ds == DailyQuotes || ds == ListedInfo
+}
+
+// Watermark はデータが途切れずに揃っている期間。日付は "2006-01-02" 形式
+type Watermark struct {
+	Since    string    `json:"since"`
+	Through  string    `json:"through"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// Store は倉庫のディレクトリ。同じディレクトリを複数のプロセスから同時に Sync しないこと
+type Store struct {
+	dir string
+	mu  sync.Mutex // watermarks.json の読み書きを直列化する
+}
+
+// Open は dir を倉庫として開く。ディレクトリが無ければ作る
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create warehouse directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Dir は倉庫のディレクトリを返す
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func (s *Store) calendarPath() string {
+	return filepath.Join(s.dir, "calendar.json")
+}
+
+func (s *Store) watermarksPath() string {
+	return filepath.Join(s.dir, "watermarks.json")
+}
+
+// datePath は1日分のファイルの場所。1ディレクトリのファイル数を抑えるため年ごとに分ける
+func (s *Store) datePath(ds Dataset, d time.Time) string {
+	d = d.In(jquants.JST)
+	return filepath.Join(s.dir, string(ds), d.Format("2006"), jquants.DateOf(d).String()+".json.gz")
+}
+
+// codePath は銘柄ごとに取得した財務情報のファイルの場所
+func (s *Store) codePath(code string) string {
+	return filepath.Join(s.dir, string(Statements), "codes", code+".json.gz")
+}
+
+// Has は ds の d の日付分が保存済みかどうかを返す
+func (s *Store) Has(ds Dataset, d time.Time) bool {
+	_, err := os.Stat(s.datePath(ds, d))
+	return err == nil
+}
+
+// Calendar は Sync で保存した営業日カレンダーを返す
+func (s *Store) Calendar() (*jquants.Calendar, error) {
+	return jquants.LoadCalendar(s.calendarPath())
+}
+
+// Watermarks はデータごとの揃っている期間を返す。まだ Sync していなければ空
+func (s *Store) Watermarks() (map[Dataset]Watermark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadWatermarks()
+}
+
+func (s *Store) loadWatermarks() (map[Dataset]Watermark, error) {
+	wms := make(map[Dataset]Watermark)
+	b, err := os.ReadFile(s.watermarksPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return wms, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &wms); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.watermarksPath(), err)
+	}
+	return wms, nil
+}
+
+func (s *Store) setWatermark(ds Dataset, wm Watermark) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wms, err := s.loadWatermarks()
+	if err != nil {
+		return err
+	}
+	wms[ds] = wm
+	b, err := json.MarshalIndent(wms, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.watermarksPath(), b)
+}
+
+// loadDate は ds の d の日付分を読む。保存されていなければ ok = false
+func loadDate[T any](s *Store, ds Dataset, d time.Time) (items []T, ok bool, err error) {
+	ok, err = readGzipJSON(s.datePath(ds, d), &items)
+	return items, ok, err
+}
+
+// saveDate は ds の d の日付分を保存する
+func saveDate[T any](s *Store, ds Dataset, d time.Time, items []T) error {
+	if items == nil {
+		items = []T{}
+	}
+	return writeGzipJSON(s.datePath(ds, d), items)
+}
+
+// readGzipJSON は path を v に読み込む。ファイルが無ければ false
+func readGzipJSON(path string, v any) (bool, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer zr.Close()
+	if err := json.NewDecoder(zr).Decode(v); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// writeGzipJSON は v を JSON にして gzip で圧縮し、path に保存する
+func writeGzipJSON(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// writeFileAtomic は一時ファイルに書いてから置き換える。途中で落ちても壊れたファイルが残らない
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// matchCode は J-Quants の5桁の銘柄コードが、4桁または5桁で指定された code に一致するかどうかを返す
+func matchCode(itemCode, code string) bool {
+	return code == "" || itemCode == code || (len(code) == 4 && itemCode == code+"0")
+}
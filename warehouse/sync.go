@@ -0,0 +1,247 @@
+package warehouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+
+	jquants "Go-AutoTrade/j-quants"
+)
+
+// Source は倉庫にデータを取り込む元。*jquants.JQuantsClient がそのまま使える
+type Source interface {
+	GetDailyQuotesWithContext(ctx context.Context, params jquants.GetDailyQuotesParams) ([]jquants.DailyQuote, error)
+	GetStatementsWithContext(ctx context.Context, params jquants.GetStatementsParams) ([]jquants.Statement, error)
+	GetListedInfoWithContext(ctx context.Context, params jquants.GetListedInfoParams) ([]jquants.ListedInfo, error)
+	GetCalendar(ctx context.Context, cachePath string, from, to time.Time) (*jquants.Calendar, error)
+}
+
+// SyncOptions は Sync の対象期間とデータ
+type SyncOptions struct {
+	// From は取得を始める日。ゼロ値なら前回の Watermark.Through の翌日から。初回は必須
+	From time.Time
+	// To は取得する最後の日。ゼロ値または当日以降なら前日まで (当日分はまだ確定していないため取らない)
+	To time.Time
+	// Datasets は対象のデータ。空ならすべて。ListedInfo は期間の最終営業日時点の一覧だけを取る
+	Datasets []Dataset
+	// Now は現在時刻。テスト用で、nil なら time.Now
+	Now func() time.Time
+}
+
+// SyncResult はデータごとの Sync の結果
+type SyncResult struct {
+	Dataset   Dataset
+	Fetched   []string // 取得した日付
+	Skipped   int      // 保存済みで取得しなかった日数
+	Records   int      // 取得したレコード数
+	Watermark Watermark
+	// Restricted は契約プランで取得できなかったかどうか。その場合もほかのデータの取得は続ける
+	Restricted bool
+}
+
+// Sync は src から足りない日付の分だけを取得して保存する。
+// 途中でエラーになっても、それまでに保存した分と Watermark は残るので、もう一度呼べば続きから取得できる。
+// 契約プランで取得できないデータ (jquants.ErrPlanRestricted) は SyncResult.Restricted を立てて飛ばす
+func (s *Store) Sync(ctx context.Context, src Source, opts SyncOptions) ([]SyncResult, error) {
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	today := jquants.DateOf(now()).Time
+	to := jquants.DateOf(opts.To).Time
+	if opts.To.IsZero() || !to.Before(today) {
+		to = today.AddDate(0, 0, -1)
+	}
+	datasets := opts.Datasets
+	if len(datasets) == 0 {
+		datasets = AllDatasets
+	}
+
+	wms, err := s.Watermarks()
+	if err != nil {
+		return nil, err
+	}
+
+	// データごとの開始日を決め、カレンダーはそのすべてを含む範囲で用意する
+	froms := make(map[Dataset]time.Time)
+	calFrom := to
+	for _, ds := range datasets {
+		from, err := syncFrom(opts.From, wms[ds], ds)
+		if err != nil {
+			return nil, err
+		}
+		froms[ds] = from
+		if since, err := jquants.ParseDate(wms[ds].Since); err == nil && since.Before(from) {
+			from = since
+		}
+		if from.Before(calFrom) {
+			calFrom = from
+		}
+	}
+	cal, err := s.syncCalendar(ctx, src, calFrom, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SyncResult
+	for _, ds := range datasets {
+		res, err := s.syncDataset(ctx, src, cal, ds, froms[ds], to, wms[ds], now())
+		if errors.Is(err, jquants.ErrPlanRestricted) {
+			log.Printf("[WARN] %v. Skipping %s.", err, ds)
+			res.Restricted = true
+			err = nil
+		}
+		results = append(results, res)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// syncCalendar は from から to までを途切れずに含むカレンダーを返す。
+// 途中が欠けたカレンダーでは欠けた期間の営業日が無いものとして扱われてしまうので、
+// 保存済みのカレンダーを消して一度だけ取り直し、それでも欠けていればエラーにする
+func (s *Store) syncCalendar(ctx context.Context, src Source, from, to time.Time) (*jquants.Calendar, error) {
+	for retried := false; ; retried = true {
+		cal, err := src.GetCalendar(ctx, s.calendarPath(), from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get trading calendar: %w", err)
+		}
+		if cal.Covers(from) && cal.Covers(to) && cal.Contiguous() {
+			return cal, nil
+		}
+		if retried {
+			return nil, fmt.Errorf("trading calendar for %s to %s is incomplete", jquants.DateOf(from), jquants.DateOf(to))
+		}
+		log.Printf("[WARN] Trading calendar has gaps. Fetching it again.")
+		if err := os.Remove(s.calendarPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove trading calendar cache: %w", err)
+		}
+	}
+}
+
+// syncFrom は ds の取得を始める日を返す
+func syncFrom(from time.Time, wm Watermark, ds Dataset) (time.Time, error) {
+	if !from.IsZero() {
+		return jquants.DateOf(from).Time, nil
+	}
+	through, err := jquants.ParseDate(wm.Through)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s has not been synced yet; SyncOptions.From is required", ds)
+	}
+	return through.AddDate(0, 0, 1), nil
+}
+
+func (s *Store) syncDataset(ctx context.Context, src Source, cal *jquants.Calendar, ds Dataset, from, to time.Time, prev Watermark, now time.Time) (SyncResult, error) {
+	res := SyncResult{Dataset: ds, Watermark: prev}
+
+	days := cal.TradingDaysBetween(from, to)
+	if ds == ListedInfo && len(days) > 0 {
+		days = days[len(days)-1:]
+	}
+
+	var syncErr error
+	for _, d := range days {
+		if s.Has(ds, d) {
+			res.Skipped++
+			continue
+		}
+		n, err := s.fetchDate(ctx, src, ds, d)
+		if err != nil {
+			syncErr = fmt.Errorf("failed to sync %s for %s: %w", ds, jquants.DateOf(d), err)
+			break
+		}
+		if n < 0 {
+			log.Printf("[WARN] No %s for %s yet. Skipping.", ds, jquants.DateOf(d))
+			continue
+		}
+		res.Fetched = append(res.Fetched, jquants.DateOf(d).String())
+		res.Records += n
+	}
+	if len(res.Fetched) > 0 {
+		log.Printf("[INFO] Synced %s: %d days, %d records.", ds, len(res.Fetched), res.Records)
+	}
+
+	wm, ok := s.coverage(cal, ds, prev, from, to)
+	if ok {
+		wm.SyncedAt = now
+		if err := s.setWatermark(ds, wm); err != nil && syncErr == nil {
+			syncErr = fmt.Errorf("failed to save watermark: %w", err)
+		}
+		res.Watermark = wm
+	}
+	return res, syncErr
+}
+
+// fetchDate は ds の d の日付分を取得して保存し、件数を返す。保存しなかった場合は -1
+func (s *Store) fetchDate(ctx context.Context, src Source, ds Dataset, d time.Time) (int, error) {
+	date := jquants.DateOf(d).String()
+	switch ds {
+	case DailyQuotes:
+		items, err := src.GetDailyQuotesWithContext(ctx, jquants.GetDailyQuotesParams{Date: date})
+		return saveFetched(s, ds, d, items, err)
+	case Statements:
+		items, err := src.GetStatementsWithContext(ctx, jquants.GetStatementsParams{Date: date})
+		return saveFetched(s, ds, d, items, err)
+	case ListedInfo:
+		items, err := src.GetListedInfoWithContext(ctx, jquants.GetListedInfoParams{Date: date})
+		return saveFetched(s, ds, d, items, err)
+	}
+	return 0, fmt.Errorf("unknown dataset: %s", ds)
+}
+
+func saveFetched[T any](s *Store, ds Dataset, d time.Time, items []T, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	if len(items) == 0 && ds.alwaysHasData() {
+		return -1, nil
+	}
+	if err := saveDate(s, ds, d, items); err != nil {
+		return 0, err
+	}
+	return len(items), nil
+}
+
+// coverage は保存済みのファイルから、途切れずに揃っている期間を求める。
+// 前回の Since から数え始めるので、前回との間に隙間があれば Through は隙間の手前で止まる
+func (s *Store) coverage(cal *jquants.Calendar, ds Dataset, prev Watermark, from, to time.Time) (Watermark, bool) {
+	if ds == ListedInfo {
+		// 一覧はその日時点のスナップショットなので、最新の日付だけを記録する
+		days := cal.TradingDaysBetween(from, to)
+		for i := len(days) - 1; i >= 0; i-- {
+			if s.Has(ds, days[i]) {
+				date := jquants.DateOf(days[i]).String()
+				return Watermark{Since: date, Through: date}, true
+			}
+		}
+		return prev, prev.Through != ""
+	}
+
+	since := from
+	if t, err := jquants.ParseDate(prev.Since); err == nil && t.Before(since) {
+		since = t
+	}
+	var first, last time.Time
+	for _, d := range cal.TradingDaysBetween(since, to) {
+		if !s.Has(ds, d) {
+			if first.IsZero() {
+				continue // 前回の Since より前の隙間は飛ばす
+			}
+			break
+		}
+		if first.IsZero() {
+			first = d
+		}
+		last = d
+	}
+	if first.IsZero() {
+		return prev, prev.Through != ""
+	}
+	return Watermark{Since: jquants.DateOf(first).String(), Through: jquants.DateOf(last).String()}, true
+}
@@ -0,0 +1,301 @@
+package warehouse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	jquants "Go-AutoTrade/j-quants"
+)
+
+// fakeSource は日付ごとのデータを返すだけの取得元
+type fakeSource struct {
+	calendar    map[string]jquants.HolidayDivision // 土日以外の休日と半日立会日
+	quotes      map[string][]jquants.DailyQuote
+	statements  map[string][]jquants.Statement
+	byCode      map[string][]jquants.Statement
+	calls       int
+	codeQueries int
+
+	statementsErr error // 設定されていれば日付指定の財務情報の取得でこれを返す
+}
+
+func (f *fakeSource) GetDailyQuotesWithContext(ctx context.Context, params jquants.GetDailyQuotesParams) ([]jquants.DailyQuote, error) {
+	f.calls++
+	return f.quotes[params.Date], nil
+}
+
+func (f *fakeSource) GetStatementsWithContext(ctx context.Context, params jquants.GetStatementsParams) ([]jquants.Statement, error) {
+	f.calls++
+	if params.Code != "" {
+		f.codeQueries++
+		return f.byCode[params.Code], nil
+	}
+	if f.statementsErr != nil {
+		return nil, f.statementsErr
+	}
+	return f.statements[params.Date], nil
+}
+
+func (f *fakeSource) GetListedInfoWithContext(ctx context.Context, params jquants.GetListedInfoParams) ([]jquants.ListedInfo, error) {
+	f.calls++
+	return []jquants.ListedInfo{{Date: params.Date, Code: "72030"}, {Date: params.Date, Code: "13010"}}, nil
+}
+
+// GetCalendar は from から to までのカレンダーを作り、保存済みの分と単純に合わせて保存する。
+// 間の期間を埋めないので、離れた期間を続けて要求すると途中の欠けたカレンダーになる
+func (f *fakeSource) GetCalendar(ctx context.Context, cachePath string, from, to time.Time) (*jquants.Calendar, error) {
+	cached, err := jquants.LoadCalendar(cachePath)
+	if err == nil && cached.Covers(from) && cached.Covers(to) {
+		return cached, nil
+	}
+	var days []jquants.TradingCalendarDay
+	if cached != nil {
+		days = cached.Days()
+	}
+	for d := jquants.DateOf(from).Time; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := jquants.DateOf(d).String()
+		div, ok := f.calendar[date]
+		if !ok {
+			div = jquants.HolidayDivisionBusinessDay
+			if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+				div = jquants.HolidayDivisionNonBusinessDay
+			}
+		}
+		days = append(days, jquants.TradingCalendarDay{Date: date, HolidayDivision: div})
+	}
+	cal, err := jquants.NewCalendar(days)
+	if err != nil {
+		return nil, err
+	}
+	return cal, cal.Save(cachePath)
+}
+
+func newFakeSource() *fakeSource {
+	f := &fakeSource{
+		calendar:   make(map[string]jquants.HolidayDivision),
+		quotes:     make(map[string][]jquants.DailyQuote),
+		statements: make(map[string][]jquants.Statement),
+		byCode:     make(map[string][]jquants.Statement),
+	}
+	for _, d := range []struct {
+		date string
+		div  jquants.HolidayDivision
+	}{
+		{"2024-01-04", jquants.HolidayDivisionHalfDay},
+		{"2024-01-05", jquants.HolidayDivisionBusinessDay},
+		{"2024-01-06", jquants.HolidayDivisionNonBusinessDay},
+		{"2024-01-07", jquants.HolidayDivisionNonBusinessDay},
+		{"2024-01-08", jquants.HolidayDivisionNonBusinessDay},
+		{"2024-01-09", jquants.HolidayDivisionBusinessDay},
+		{"2024-01-10", jquants.HolidayDivisionBusinessDay},
+		{"2024-01-11", jquants.HolidayDivisionBusinessDay},
+		{"2024-01-12", jquants.HolidayDivisionBusinessDay},
+	} {
+		f.calendar[d.date] = d.div
+		day, _ := jquants.ParseDate(d.date)
+		f.quotes[d.date] = []jquants.DailyQuote{
+			{Date: jquants.DateOf(day), Code: "72030", Close: jquants.Float(2500)},
+			{Date: jquants.DateOf(day), Code: "13010", Close: jquants.Float(3900)},
+		}
+		f.statements[d.date] = []jquants.Statement{{DisclosedDate: d.date, LocalCode: "72030", DisclosureNumber: d.date}}
+	}
+	return f
+}
+
+func at(date string, hour int) func() time.Time {
+	d, _ := jquants.ParseDate(date)
+	return func() time.Time { return d.Add(time.Duration(hour) * time.Hour) }
+}
+
+func TestSyncFetchesOnlyMissingDates(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := newFakeSource()
+	from, _ := jquants.ParseDate("2024-01-04")
+
+	results, err := store.Sync(context.Background(), src, SyncOptions{From: from, Now: at("2024-01-10", 10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 当日 (01-10) は取らず、01-04, 01-05, 01-09 の3営業日分。一覧は最終営業日の分だけ
+	if len(results) != 3 || len(results[0].Fetched) != 3 || len(results[1].Fetched) != 3 || len(results[2].Fetched) != 1 {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	wms, err := store.Watermarks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wms[DailyQuotes].Since != "2024-01-04" || wms[DailyQuotes].Through != "2024-01-09" || wms[ListedInfo].Through != "2024-01-09" {
+		t.Errorf("Unexpected watermarks: %+v", wms)
+	}
+
+	// 翌日の Sync は前回の続きの1日分だけを取る。まだ公開されていない日足は保存しない
+	src.calls = 0
+	delete(src.quotes, "2024-01-10")
+	results, err = store.Sync(context.Background(), src, SyncOptions{Now: at("2024-01-11", 10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results[0].Fetched) != 0 || len(results[1].Fetched) != 1 || src.calls != 3 {
+		t.Errorf("Expected only 2024-01-10 to be requested, got %+v (calls=%d)", results, src.calls)
+	}
+	if wms, _ := store.Watermarks(); wms[DailyQuotes].Through != "2024-01-09" || wms[Statements].Through != "2024-01-10" {
+		t.Errorf("Unexpected watermarks: %+v", wms)
+	}
+}
+
+func TestSyncSkipsPlanRestrictedDatasets(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := newFakeSource()
+	src.statementsErr = &jquants.APIError{StatusCode: http.StatusForbidden, Message: "This API is not available on your subscription."}
+	from, _ := jquants.ParseDate("2024-01-04")
+
+	results, err := store.Sync(context.Background(), src, SyncOptions{From: from, Now: at("2024-01-10", 10)})
+	if err != nil {
+		t.Fatalf("Expected plan-restricted statements to be skipped, got: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	// 財務情報だけ飛ばし、日足と一覧は取得する
+	if !results[1].Restricted || len(results[1].Fetched) != 0 {
+		t.Errorf("Expected statements to be marked restricted, got %+v", results[1])
+	}
+	if results[0].Restricted || len(results[0].Fetched) != 3 || results[2].Restricted || len(results[2].Fetched) != 1 {
+		t.Errorf("Expected other datasets to be synced, got %+v", results)
+	}
+
+	// プランの制限以外のエラーではそこで止まる
+	src.statementsErr = errors.New("connection reset")
+	if _, err := store.Sync(context.Background(), src, SyncOptions{From: from, Now: at("2024-01-11", 10)}); err == nil {
+		t.Error("Expected other errors to stop the sync")
+	}
+}
+
+func TestSyncRequiresFromOnFirstRun(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Sync(context.Background(), newFakeSource(), SyncOptions{}); err == nil {
+		t.Error("Expected error when From is missing on the first sync")
+	}
+}
+
+func TestSyncFillsGapAcrossSeparateRanges(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := newFakeSource()
+	first, _ := jquants.ParseDate("2020-01-01")
+	last, _ := jquants.ParseDate("2024-06-28")
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		date := jquants.DateOf(d).String()
+		src.quotes[date] = []jquants.DailyQuote{{Date: jquants.DateOf(d), Code: "72030", Close: jquants.Float(2500)}}
+	}
+	now := at("2024-07-01", 10)
+	ctx := context.Background()
+
+	to, _ := jquants.ParseDate("2020-02-29")
+	if _, err := store.Sync(ctx, src, SyncOptions{From: first, To: to, Datasets: []Dataset{DailyQuotes}, Now: now}); err != nil {
+		t.Fatal(err)
+	}
+	// 離れた期間の一覧だけを取っても、保存されるカレンダーは途切れない
+	from, _ := jquants.ParseDate("2024-06-01")
+	if _, err := store.Sync(ctx, src, SyncOptions{From: from, To: last, Datasets: []Dataset{ListedInfo}, Now: now}); err != nil {
+		t.Fatal(err)
+	}
+	if cal, err := store.Calendar(); err != nil || !cal.Contiguous() {
+		t.Fatalf("Expected a contiguous calendar, got err=%v", err)
+	}
+
+	// 間の期間の日足も取得し、Watermark は実際に揃った所までにする
+	results, err := store.Sync(ctx, src, SyncOptions{To: last, Datasets: []Dataset{DailyQuotes}, Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mid, _ := jquants.ParseDate("2022-03-01")
+	if !store.Has(DailyQuotes, mid) || len(results[0].Fetched) < 1000 {
+		t.Errorf("Expected the gap to be fetched, got %d days", len(results[0].Fetched))
+	}
+	if wms, _ := store.Watermarks(); wms[DailyQuotes].Since != "2020-01-01" || wms[DailyQuotes].Through != "2024-06-28" {
+		t.Errorf("Unexpected watermarks: %+v", wms)
+	}
+}
+
+func TestReaderPrefersLocalData(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := newFakeSource()
+	from, _ := jquants.ParseDate("2024-01-04")
+	if _, err := store.Sync(context.Background(), src, SyncOptions{From: from, Now: at("2024-01-11", 10)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 倉庫だけを引く Reader
+	local := store.Reader(nil)
+	quotes, err := local.GetDailyQuotesWithContext(context.Background(), jquants.GetDailyQuotesParams{Code: "7203", From: "2024-01-04", To: "2024-01-10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 4 || quotes[0].Code != "72030" {
+		t.Errorf("Unexpected local quotes: %+v", quotes)
+	}
+	if _, err := local.GetDailyQuotesWithContext(context.Background(), jquants.GetDailyQuotesParams{Date: "2024-01-11"}); !errors.Is(err, ErrNotInWarehouse) {
+		t.Errorf("Expected ErrNotInWarehouse, got %v", err)
+	}
+	infos, err := local.GetListedInfoWithContext(context.Background(), jquants.GetListedInfoParams{Code: "1301"})
+	if err != nil || len(infos) != 1 || infos[0].Date != "2024-01-10" {
+		t.Errorf("Expected the latest listed info snapshot, got %+v (err=%v)", infos, err)
+	}
+
+	// 銘柄指定の財務情報は、一度取得すれば Sync が追いついている間は倉庫から返す
+	src.byCode["7203"] = []jquants.Statement{{DisclosedDate: "2023-11-01", LocalCode: "72030", DisclosureNumber: "old"}}
+	reader := store.Reader(src)
+	reader.now = at("2024-01-11", 10)
+	for i := 0; i < 2; i++ {
+		if _, err := reader.GetStatementsWithContext(context.Background(), jquants.GetStatementsParams{Code: "7203"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if src.codeQueries != 1 {
+		t.Errorf("Expected the second query to be served locally, got %d remote queries", src.codeQueries)
+	}
+
+	// 01-11 と 01-12 の分を Sync すれば、取得済みの分とあわせて倉庫から返す
+	if _, err := store.Sync(context.Background(), src, SyncOptions{Now: at("2024-01-13", 10)}); err != nil {
+		t.Fatal(err)
+	}
+	reader.now = at("2024-01-13", 10)
+	statements, err := reader.GetStatementsWithContext(context.Background(), jquants.GetStatementsParams{Code: "7203"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.codeQueries != 1 || len(statements) != 3 || statements[0].DisclosureNumber != "old" {
+		t.Errorf("Expected local statements merged with synced days, got %d statements (queries=%d)", len(statements), src.codeQueries)
+	}
+
+	// Sync に欠けがあれば API に問い合わせる
+	d, _ := jquants.ParseDate("2024-01-12")
+	if err := os.Remove(store.datePath(Statements, d)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.GetStatementsWithContext(context.Background(), jquants.GetStatementsParams{Code: "7203"}); err != nil {
+		t.Fatal(err)
+	}
+	if src.codeQueries != 2 {
+		t.Errorf("Expected a remote query when the warehouse is behind, got %d", src.codeQueries)
+	}
+}